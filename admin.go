@@ -0,0 +1,22 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DB 연결 풀 상태 조회 (운영 진단용, API 키 필요)
+//
+// db.Stats()를 그대로 JSON으로 응답한다 (OpenConnections, InUse, Idle, WaitCount, WaitDuration 등).
+// 수집/그래프에 쓸 수 있도록 가공하지 않으며 WaitDuration은 나노초 단위 정수다.
+// 메모리 저장소로 실행 중이면 연결 풀이 없으므로 404로 응답한다.
+func GetDBStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if db == nil {
+		writeError(w, r, http.StatusNotFound, "DB 연결 풀을 사용하지 않습니다 (DB_DRIVER=memory)", nil)
+		return
+	}
+
+	json.NewEncoder(w).Encode(db.Stats())
+}
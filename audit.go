@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// 변경 이력 테이블 (시작 시 DB_TABLE 이름에 _audit를 붙여 책 테이블과 같은 카탈로그/스키마로 설정)
+//
+// 책 추가/수정/삭제와 같은 트랜잭션에서 한 행씩 기록하므로 이 테이블이 없으면 쓰기 요청이 실패한다.
+// 필요한 테이블:
+//
+//	CREATE TABLE bz.dbo.tbl_book_audit (
+//		id         bigint IDENTITY PRIMARY KEY,
+//		book_id    int           NOT NULL,
+//		operation  varchar(10)   NOT NULL, -- create, update, delete
+//		old_values nvarchar(max) NULL,     -- 변경 전 책 (JSON)
+//		new_values nvarchar(max) NULL,     -- 변경 후 책 (JSON)
+//		actor      nvarchar(200) NOT NULL,
+//		changed_at datetime2     NOT NULL,
+//		tenant_id  nvarchar(100) NULL,     -- 멀티 테넌시 사용 시
+//		INDEX ix_tbl_book_audit_book_id (book_id, id)
+//	)
+var auditTable = "bz.dbo.tbl_book_audit"
+
+// 변경 종류
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+// 변경 이력 항목 (추가는 old_values, 삭제는 new_values가 null)
+type auditEntry struct {
+	ID        int64  `json:"id" xml:"id"`
+	BookID    string `json:"book_id" xml:"book_id"`
+	Operation string `json:"operation" xml:"operation"`
+	OldValues *Book  `json:"old_values" xml:"old_values,omitempty"`
+	NewValues *Book  `json:"new_values" xml:"new_values,omitempty"`
+	Actor     string `json:"actor" xml:"actor"`
+	ChangedAt string `json:"changed_at" xml:"changed_at"` // RFC3339
+}
+
+// 필드 하나의 변경 이력 (?field=)
+type fieldHistory struct {
+	Field string `json:"field" xml:"field"`
+	// 추가 이후 값이 바뀐 적이 있는지와 마지막으로 바뀐 시각 (바뀐 적이 없으면 null)
+	Changed       bool          `json:"changed" xml:"changed"`
+	LastChangedAt *string       `json:"last_changed_at" xml:"last_changed_at,omitempty"`
+	Changes       []fieldChange `json:"changes" xml:"change"`
+}
+
+// 필드 값 변경 한 건 (추가는 old_value가 null)
+type fieldChange struct {
+	Operation string      `json:"operation" xml:"operation"`
+	OldValue  interface{} `json:"old_value" xml:"old_value,omitempty"`
+	NewValue  interface{} `json:"new_value" xml:"new_value"`
+	Actor     string      `json:"actor" xml:"actor"`
+	ChangedAt string      `json:"changed_at" xml:"changed_at"`
+}
+
+// 이력 스냅샷에서 필드 하나의 변경만 추림
+// 추가 시의 값과, 수정으로 값이 실제로 바뀐 항목만 남긴다 (삭제는 값 변경이 아니므로 제외).
+func buildFieldHistory(history []auditEntry, field string) fieldHistory {
+	result := fieldHistory{Field: field, Changes: []fieldChange{}}
+	for _, entry := range history {
+		if entry.NewValues == nil {
+			continue
+		}
+		change := fieldChange{
+			Operation: entry.Operation,
+			NewValue:  entry.NewValues.fieldValue(field),
+			Actor:     entry.Actor,
+			ChangedAt: entry.ChangedAt,
+		}
+		if entry.OldValues != nil {
+			change.OldValue = entry.OldValues.fieldValue(field)
+			if change.OldValue == change.NewValue {
+				continue
+			}
+			result.Changed = true
+			changedAt := entry.ChangedAt
+			result.LastChangedAt = &changedAt
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result
+}
+
+// 변경을 만든 클라이언트와 테넌트 (쓰기 큐는 요청별로 보관했다가 워커에서 기록)
+type auditSource struct {
+	Actor  string
+	Tenant string
+}
+
+// 요청 컨텍스트의 변경 주체
+// JWT는 "jwt:"+sub, API 키는 키 자체를 남기지 않도록 "key:"+SHA-256 앞 12자리로 기록한다.
+func auditSourceFromContext(ctx context.Context) auditSource {
+	source := auditSource{Tenant: tenantFromContext(ctx)}
+	id, _ := ctx.Value(clientIDKey).(string)
+	switch {
+	case strings.HasPrefix(id, "jwt:"):
+		source.Actor = id
+	case id != "":
+		source.Actor = "key:" + hashAPIKey(id)[:12]
+	}
+	return source
+}
+
+// 변경 이력 한 행 기록 (책 변경과 같은 트랜잭션 tx에서 호출)
+func insertAudit(ctx context.Context, tx *sql.Tx, source auditSource, operation string, oldBook, newBook *Book) error {
+	bookID := ""
+	var oldValues, newValues interface{}
+	if oldBook != nil {
+		bookID = oldBook.ID
+		data, err := json.Marshal(oldBook)
+		if err != nil {
+			return err
+		}
+		oldValues = string(data)
+	}
+	if newBook != nil {
+		bookID = newBook.ID
+		data, err := json.Marshal(newBook)
+		if err != nil {
+			return err
+		}
+		newValues = string(data)
+	}
+
+	columns := "book_id, operation, old_values, new_values, actor, changed_at"
+	values := "?, ?, ?, ?, ?, GETUTCDATE()"
+	args := []interface{}{bookID, operation, oldValues, newValues, source.Actor}
+	if source.Tenant != "" {
+		columns += ", tenant_id"
+		values += ", ?"
+		args = append(args, source.Tenant)
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO "+auditTable+" ("+columns+") VALUES ("+values+")", args...)
+	return err
+}
+
+// 변경 이력 행 스캔 (old_values/new_values JSON을 책으로 변환)
+func scanAuditEntry(row rowScanner) (auditEntry, error) {
+	var entry auditEntry
+	var oldValues, newValues sql.NullString
+	var changedAt sql.NullTime
+	if err := row.Scan(&entry.ID, &entry.BookID, &entry.Operation, &oldValues, &newValues, &entry.Actor, &changedAt); err != nil {
+		return auditEntry{}, err
+	}
+	var err error
+	if entry.OldValues, err = decodeAuditBook(oldValues); err != nil {
+		return auditEntry{}, err
+	}
+	if entry.NewValues, err = decodeAuditBook(newValues); err != nil {
+		return auditEntry{}, err
+	}
+	if changedAt.Valid {
+		entry.ChangedAt = formatTimestamp(changedAt.Time)
+	}
+	return entry, nil
+}
+
+// 이력에 저장한 책 JSON 해석 (NULL이면 nil)
+func decodeAuditBook(raw sql.NullString) (*Book, error) {
+	if !raw.Valid {
+		return nil, nil
+	}
+	var book Book
+	if err := json.Unmarshal([]byte(raw.String), &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// 책 변경 이력 조회 (오래된 순)
+// 삭제된 책의 이력도 조회할 수 있다. 이력이 없으면 책이 있을 때는 빈 배열, 없을 때는 404로 응답한다.
+// ?field=title처럼 수정 가능한 필드를 지정하면 그 필드의 값 변경만 fieldHistory로 응답한다.
+func GetBookHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	field := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("field")))
+	if r.URL.Query().Has("field") {
+		var editable []string
+		for _, f := range bookFieldDefs() {
+			if !f.ReadOnly {
+				editable = append(editable, f.Name)
+			}
+		}
+		if !slices.Contains(editable, field) {
+			writeError(w, r, http.StatusBadRequest, "field는 "+strings.Join(editable, ", ")+" 중 하나여야 합니다", nil)
+			return
+		}
+	}
+
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	history, err := repo.History(ctx, id)
+	if err != nil {
+		requestLogger(r.Context()).Error("변경 이력 조회 에러", "error", err)
+		writeDBError(w, r, err, "변경 이력 조회 실패")
+		return
+	}
+	if len(history) == 0 {
+		_, err := repo.Get(ctx, id, true)
+		if err == errBookNotFound {
+			writeError(w, r, http.StatusNotFound, "책을 찾을 수 없습니다", nil)
+			return
+		}
+		if err != nil {
+			requestLogger(r.Context()).Error("조회 에러", "error", err)
+			writeDBError(w, r, err, "변경 이력 조회 실패")
+			return
+		}
+	}
+
+	if field != "" {
+		writeFormatted(w, format, buildFieldHistory(history, field), "field_history", "")
+		return
+	}
+	writeFormatted(w, format, history, "history", "entry")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+type testFieldHistory struct {
+	Field         string  `json:"field"`
+	Changed       bool    `json:"changed"`
+	LastChangedAt *string `json:"last_changed_at"`
+	Changes       []struct {
+		Operation string      `json:"operation"`
+		OldValue  interface{} `json:"old_value"`
+		NewValue  interface{} `json:"new_value"`
+		ChangedAt string      `json:"changed_at"`
+	} `json:"changes"`
+}
+
+func TestBookFieldHistory(t *testing.T) {
+	h := newTestHandler(t, nil)
+	created := createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"year":1973}`, nil)
+	doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"title":"토지 1부"}`, nil)
+	doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"year":1994}`, nil)
+
+	rec := doRequest(t, h, "GET", "/v1/books/"+created.ID+"/history?field=year", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var year testFieldHistory
+	decodeResponse(t, rec, &year)
+	if year.Field != "year" || !year.Changed || len(year.Changes) != 3 {
+		t.Fatalf("year 이력 = %+v", year)
+	}
+	if year.Changes[0].Operation != auditCreate || year.Changes[0].OldValue != nil || year.Changes[0].NewValue != float64(1969) {
+		t.Errorf("첫 항목 = %+v, 원하는 값 추가 시 1969", year.Changes[0])
+	}
+	last := year.Changes[2]
+	if last.OldValue != float64(1973) || last.NewValue != float64(1994) {
+		t.Errorf("마지막 변경 = %+v, 원하는 값 1973 -> 1994", last)
+	}
+	if year.LastChangedAt == nil || *year.LastChangedAt != last.ChangedAt {
+		t.Errorf("last_changed_at = %v, 원하는 값 %q", year.LastChangedAt, last.ChangedAt)
+	}
+
+	// 한 번도 바뀌지 않은 필드는 추가 시의 값만 있고 last_changed_at이 null
+	rec = doRequest(t, h, "GET", "/v1/books/"+created.ID+"/history?field=author", "", nil)
+	var author testFieldHistory
+	decodeResponse(t, rec, &author)
+	if author.Changed || author.LastChangedAt != nil || len(author.Changes) != 1 || author.Changes[0].NewValue != "박경리" {
+		t.Errorf("author 이력 = %+v", author)
+	}
+
+	for _, field := range []string{"", "version", "titel"} {
+		rec = doRequest(t, h, "GET", "/v1/books/"+created.ID+"/history?field="+field, "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("field=%q 상태 코드 = %d, 원하는 값 400", field, rec.Code)
+		}
+	}
+
+	rec = doRequest(t, h, "GET", "/v1/books/999/history?field=title", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("없는 책 상태 코드 = %d, 원하는 값 404", rec.Code)
+	}
+}
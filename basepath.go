@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// 기본 경로 미들웨어 (BASE_PATH, 비어 있으면 사용 안 함)
+//
+// 공유 인그레스 아래에 /catalog 같은 접두사로 배포할 때 사용한다.
+// 접두사를 떼어 라우터에 넘기므로 핸들러와 라우트는 그대로 /books, /health를 본다.
+// 접두사로 시작하지 않는 요청은 404로 응답한다.
+func basePathMiddleware(basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if basePath == "" {
+			return next
+		}
+		strip := http.StripPrefix(basePath, next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != basePath && !strings.HasPrefix(r.URL.Path, basePath+"/") {
+				writeError(w, r, http.StatusNotFound, "경로를 찾을 수 없습니다", nil)
+				return
+			}
+			strip.ServeHTTP(w, r)
+		})
+	}
+}
+
+// 클라이언트에서 보이는 경로 (Location 등 응답에 넣는 경로에 BASE_PATH를 붙임)
+func externalPath(path string) string {
+	if appConfig == nil {
+		return path
+	}
+	return appConfig.BasePath + path
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// JSON 요청 본문 디코딩
+// 본문 크기를 MAX_BODY_BYTES로 제한한다(초과 시 413).
+// JSON으로 해석할 수 없으면 400, JSON은 올바르지만 알 수 없는 필드나 타입이 맞지 않는 값이 있으면
+// 입력값 검증 실패와 같은 422 응답(fields에 필드별 사유)으로 거부한다.
+// 실패하면 에러 응답을 작성하고 false를 반환한다 (badRequest는 400 응답 메시지).
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}, badRequest string) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, appConfig.MaxBodyBytes)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("요청 본문은 최대 %d바이트까지 보낼 수 있습니다", tooLarge.Limit), nil)
+		return false
+	}
+	if name, ok := unknownFieldName(err); ok {
+		writeValidationError(w, r, map[string]string{name: "알 수 없는 필드입니다"})
+		return false
+	}
+	if name, message, ok := jsonFieldError(err); ok {
+		writeValidationError(w, r, map[string]string{name: message})
+		return false
+	}
+	writeError(w, r, http.StatusBadRequest, badRequest, nil)
+	return false
+}
+
+// 필드 값 형식 오류 (JSON 문법은 올바르지만 값이 필드 타입에 맞지 않음)
+type fieldTypeError struct {
+	Field   string
+	Message string
+}
+
+func (e *fieldTypeError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// 디코딩 에러에서 필드 이름과 사유 추출 (필드 타입 오류가 아니면 false)
+func jsonFieldError(err error) (string, string, bool) {
+	var fieldErr *fieldTypeError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field, fieldErr.Message, true
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return typeErr.Field, jsonTypeMessage(typeErr), true
+	}
+	return "", "", false
+}
+
+// 타입 오류 사유 (기대한 JSON 타입 기준)
+func jsonTypeMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return "형식이 올바르지 않습니다"
+	}
+	switch typeErr.Type.Kind() {
+	case reflect.String:
+		return "문자열이어야 합니다"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "정수여야 합니다"
+	case reflect.Bool:
+		return "true 또는 false여야 합니다"
+	case reflect.Slice, reflect.Array:
+		return "배열이어야 합니다"
+	case reflect.Struct, reflect.Map:
+		return "객체여야 합니다"
+	}
+	return "형식이 올바르지 않습니다"
+}
+
+// encoding/json의 알 수 없는 필드 에러에서 필드 이름 추출
+func unknownFieldName(err error) (string, bool) {
+	name, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(name, `"`), true
+}
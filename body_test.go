@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestBodyErrors(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"MAX_BODY_BYTES": "256"})
+	long := `{"title":"` + strings.Repeat("가", 200) + `","author":"박경리","year":1969}`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{"추가 본문 크기 초과", "POST", "/v1/books", long, http.StatusRequestEntityTooLarge, ""},
+		{"수정 본문 크기 초과", "PUT", "/v1/books/1", long, http.StatusRequestEntityTooLarge, ""},
+		{"추가 알 수 없는 필드", "POST", "/v1/books", `{"title":"토지","autor":"박경리","year":1969}`, http.StatusUnprocessableEntity, "autor"},
+		{"수정 알 수 없는 필드", "PUT", "/v1/books/1", `{"title":"토지","author":"박경리","year":1969,"version":1,"yaer":1970}`, http.StatusUnprocessableEntity, "yaer"},
+		{"부분 수정 알 수 없는 필드", "PATCH", "/v1/books/1", `{"titel":"토지"}`, http.StatusUnprocessableEntity, "titel"},
+		{"타입 오류", "POST", "/v1/books", `{"title":"토지","author":"박경리","year":"1969년"}`, http.StatusUnprocessableEntity, "year"},
+		{"JSON 문법 오류", "POST", "/v1/books", `{"title":`, http.StatusBadRequest, ""},
+	}
+	createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	for _, tt := range tests {
+		rec := doRequest(t, h, tt.method, tt.path, tt.body, nil)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: 상태 코드 = %d, 원하는 값 %d (%s)", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+			continue
+		}
+		if tt.wantField == "" {
+			continue
+		}
+		var body struct {
+			Fields map[string]string `json:"fields"`
+		}
+		decodeResponse(t, rec, &body)
+		if _, ok := body.Fields[tt.wantField]; !ok {
+			t.Errorf("%s: fields에 %s가 없습니다: %v", tt.name, tt.wantField, body.Fields)
+		}
+	}
+
+	// 거부된 요청은 저장소를 바꾸지 않음
+	rec := doRequest(t, h, "GET", "/v1/books/1", "", nil)
+	var book Book
+	decodeResponse(t, rec, &book)
+	if book.Title != "토지" || book.Version != 1 {
+		t.Errorf("거부된 요청 후 책 = %+v", book)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// 일괄 삭제 시 최대 ID 수 (IN 절 파라미터 수 제한)
+const maxBulkDeleteIDs = 1000
+
+// 일괄 삭제 요청
+type bulkDeleteRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// 여러 책 일괄 삭제 (하나의 트랜잭션, 없는 ID는 not_found로 반환)
+func BulkDeleteBooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req bulkDeleteRequest
+	if !decodeJSONBody(w, r, &req, "잘못된 요청 형식입니다 ({\"ids\": [...]})") {
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeValidationError(w, r, map[string]string{"ids": "삭제할 ID가 없습니다"})
+		return
+	}
+	if len(req.IDs) > maxBulkDeleteIDs {
+		writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("한 번에 최대 %d개까지 삭제할 수 있습니다", maxBulkDeleteIDs), nil)
+		return
+	}
+
+	// ID는 양의 정수여야 하며 정규화("01" -> "1") 후 중복 제거 (요청 순서 유지)
+	ids := make([]string, 0, len(req.IDs))
+	seen := map[string]struct{}{}
+	for _, raw := range req.IDs {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeValidationError(w, r, map[string]string{"ids": fmt.Sprintf("잘못된 ID입니다: %q", raw)})
+			return
+		}
+		id := strconv.Itoa(n)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	deleted, err := repo.DeleteMany(ctx, ids)
+	if isRetryableWriteError(err) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusServiceUnavailable, "동시 처리 중인 쓰기 요청이 많습니다. 잠시 후 다시 시도하세요", nil)
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("일괄 삭제 DB 에러", "error", err)
+		writeDBError(w, r, err, "책 일괄 삭제 실패")
+		return
+	}
+
+	deletedSet := map[string]struct{}{}
+	for _, id := range deleted {
+		deletedSet[id] = struct{}{}
+	}
+	notFound := []string{}
+	for _, id := range ids {
+		if _, ok := deletedSet[id]; !ok {
+			notFound = append(notFound, id)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deleted":   len(deleted),
+		"not_found": notFound,
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// 이보다 작은 응답은 압축하지 않음 (압축 이득보다 헤더/CPU 비용이 큼)
+const gzipMinSize = 1024
+
+// 이미 압축된 형식이라 다시 압축하지 않는 Content-Type
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/gzip", "application/x-gzip", "application/zip"}
+
+// gzip 응답 압축 미들웨어
+//
+// Accept-Encoding에 gzip이 있으면 응답 본문을 gzip으로 압축한다.
+// 본문이 gzipMinSize에 도달할 때까지 버퍼에 모아 두었다가 크기와 Content-Type을 보고 압축 여부를 정하므로
+// 작은 응답과 본문 없는 응답(204, 304 등)은 그대로 나간다.
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		// 핸들러가 에러/패닉으로 끝나도 남은 버퍼와 gzip 푸터를 기록
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// Accept-Encoding에 gzip 허용 여부 (q=0이면 거부로 취급)
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// 압축 응답 ResponseWriter
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer // nil이면 압축하지 않고 그대로 기록
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.status == 0 && !g.started {
+		g.status = status
+	}
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if g.started {
+		return g.write(b)
+	}
+	g.buf = append(g.buf, b...)
+	if len(g.buf) >= gzipMinSize {
+		if err := g.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (g *gzipResponseWriter) write(b []byte) (int, error) {
+	if g.gz != nil {
+		return g.gz.Write(b)
+	}
+	return g.ResponseWriter.Write(b)
+}
+
+// 압축 여부를 정하고 헤더와 버퍼에 모은 본문을 기록
+func (g *gzipResponseWriter) start() error {
+	g.started = true
+	h := g.Header()
+	if len(g.buf) >= gzipMinSize && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	if len(g.buf) == 0 {
+		return nil
+	}
+	_, err := g.write(g.buf)
+	g.buf = nil
+	return err
+}
+
+// 스트리밍 응답용 Flush (버퍼에 모인 만큼으로 압축 여부를 정함)
+func (g *gzipResponseWriter) Flush() {
+	if !g.started {
+		g.start()
+	}
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// 남은 버퍼 기록 후 gzip 스트림 종료
+func (g *gzipResponseWriter) Close() error {
+	if !g.started {
+		if err := g.start(); err != nil {
+			return err
+		}
+	}
+	if g.gz != nil {
+		return g.gz.Close()
+	}
+	return nil
+}
+
+// http.ResponseController가 원본 ResponseWriter에 접근할 수 있도록 함
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// 압축 대상 Content-Type 여부
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
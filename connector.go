@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// sql.Open("mssql")과 같은 드라이버로 만든 커넥터
+// mssql.NewConnector는 ? 자리표시자를 변환하지 않는 sqlserver 드라이버를 쓰므로
+// ?를 쓰는 쿼리가 모두 구문 오류가 난다. 등록된 "mssql" 드라이버에서 커넥터를 얻어야 한다.
+func newMSSQLConnector(connString string) (*mssql.Connector, error) {
+	registered, err := sql.Open("mssql", "")
+	if err != nil {
+		return nil, err
+	}
+	defer registered.Close()
+	return registered.Driver().(*mssql.Driver).OpenConnector(connString)
+}
+
+// 새 DB 연결 생성 속도를 제한하는 커넥터
+// 트래픽 급증이나 콜드 스타트 시 풀이 한꺼번에 연결을 열어 MSSQL 로그인이 몰리는 것을 막는다.
+// 이미 열린 연결의 재사용에는 영향이 없다.
+type throttledConnector struct {
+	driver.Connector
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // 다음 연결을 열 수 있는 시각
+}
+
+// 초당 rate개로 연결 생성을 제한하는 커넥터 생성
+func newThrottledConnector(connector driver.Connector, rate int) *throttledConnector {
+	return &throttledConnector{
+		Connector: connector,
+		interval:  time.Second / time.Duration(rate),
+	}
+}
+
+// 순서가 오면 새 연결 생성
+func (c *throttledConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.Connector.Connect(ctx)
+}
+
+// 연결 생성 슬롯 예약 후 해당 시각까지 대기
+func (c *throttledConnector) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.next
+	if slot.Before(now) {
+		slot = now
+	}
+	c.next = slot.Add(c.interval)
+	c.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DB 호출용 컨텍스트 (DB_QUERY_TIMEOUT이 설정되어 있으면 제한 시간 적용)
+func dbContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if appConfig == nil || appConfig.DBQueryTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, appConfig.DBQueryTimeout)
+}
+
+// 체크아웃 검증 시 죽은 연결을 버리고 다시 시도하는 최대 횟수
+const maxConnValidateAttempts = 3
+
+// 읽기 쿼리 실행 인터페이스 (*sql.DB, *sql.Conn)
+type readQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// 읽기 쿼리 실행
+//
+// VALIDATE_CONN_ON_CHECKOUT=true이면 풀에서 꺼낸 연결에 SELECT 1을 먼저 보내고,
+// 실패한 연결은 풀에서 폐기한 뒤 다른 연결로 다시 시도한다.
+// 네트워크가 불안정한 환경에서 재사용 연결의 첫 쿼리 실패를 줄여주지만,
+// 읽기 요청마다 DB 왕복이 한 번 더 생기므로 그만큼 지연이 늘어난다.
+func withReadConn(ctx context.Context, fn func(q readQuerier) error) error {
+	if appConfig == nil || !appConfig.ValidateConnOnCheckout {
+		return fn(db)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxConnValidateAttempts; attempt++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+
+		var one int
+		if err := conn.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				conn.Close()
+				return ctx.Err()
+			}
+			// driver.ErrBadConn을 반환하면 database/sql이 이 연결을 풀에 돌려놓지 않고 닫는다
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			conn.Close()
+			requestLogger(ctx).Warn("연결 검증 실패, 연결 폐기 후 재시도", "attempt", attempt+1, "max_attempts", maxConnValidateAttempts, "error", err)
+			continue
+		}
+
+		err = fn(conn)
+		conn.Close()
+		return err
+	}
+	return lastErr
+}
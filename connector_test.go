@@ -0,0 +1,34 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// 속도 제한 커넥터도 sql.Open("mssql")과 같은 드라이버(? 자리표시자 변환)를 써야 함
+func TestMSSQLConnectorKeepsPlaceholderRewriting(t *testing.T) {
+	registered, err := sql.Open("mssql", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registered.Close()
+	noProcess, err := sql.Open("sqlserver", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noProcess.Close()
+
+	connector, err := newMSSQLConnector("server=localhost;user id=sa;password=x;database=bz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(newThrottledConnector(connector, 5))
+	defer db.Close()
+
+	if db.Driver() != registered.Driver() {
+		t.Error("커넥터가 등록된 mssql 드라이버를 쓰지 않습니다")
+	}
+	if db.Driver() == noProcess.Driver() {
+		t.Error("커넥터가 ? 자리표시자를 변환하지 않는 sqlserver 드라이버를 씁니다")
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CSV 가져오기에 반드시 있어야 하는 헤더 컬럼 (순서 무관, isbn은 있으면 사용, 그 밖의 컬럼은 무시)
+var importCSVRequiredColumns = []string{"title", "author", "year"}
+
+// CSV 일괄 등록
+//
+// 본문은 text/csv 또는 multipart/form-data의 file 필드이며 첫 행은 헤더로 건너뛴다.
+// 헤더에 title, author, year가 있어야 하고 id, regdate 등 다른 컬럼은 무시하므로 내보내기 파일을 그대로 쓸 수 있다.
+// 기본은 잘못된 행만 건너뛰고 나머지를 배치 단위 트랜잭션으로 저장한다.
+// ?atomic=true이면 모든 행을 검증한 뒤 하나의 트랜잭션으로 저장하고, 하나라도 실패하면 아무것도 저장하지 않는다.
+// atomic 가져오기는 행을 모두 메모리에 모으므로 JSON 일괄 등록과 같이 최대 maxBatchCreateItems권으로 제한한다(초과 시 413).
+func ImportBooksCSV(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	atomic := false
+	if raw := r.URL.Query().Get("atomic"); raw != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, r, http.StatusBadRequest, "atomic은 true 또는 false여야 합니다", nil)
+			return
+		}
+	}
+
+	body, ok := csvImportBody(w, r)
+	if !ok {
+		return
+	}
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "CSV 헤더 행을 읽을 수 없습니다", nil)
+		return
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // 엑셀이 붙이는 UTF-8 BOM
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importCSVRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			writeError(w, r, http.StatusBadRequest, "CSV 헤더에 "+name+" 컬럼이 없습니다 (필수: title, author, year)", nil)
+			return
+		}
+	}
+
+	im := newBookImporter(r)
+	var all []Book
+	var allLines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			im.fail(parseErr.StartLine, "잘못된 CSV 형식입니다", nil)
+			continue
+		}
+		if err != nil {
+			requestLogger(r.Context()).Error("CSV 읽기 에러", "error", err)
+			im.fail(0, "본문 읽기 실패", nil)
+			break
+		}
+		line, _ := reader.FieldPos(0)
+
+		book, fieldErrors := csvRecordBook(record, columns)
+		if len(fieldErrors) == 0 {
+			fieldErrors = validateBook(book)
+		}
+		if len(fieldErrors) > 0 {
+			im.fail(line, "입력값 검증 실패", fieldErrors)
+			continue
+		}
+
+		if atomic {
+			if len(all) >= maxBatchCreateItems {
+				writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("atomic 가져오기는 한 번에 최대 %d권까지 등록할 수 있습니다", maxBatchCreateItems), nil)
+				return
+			}
+			all = append(all, book)
+			allLines = append(allLines, line)
+			continue
+		}
+		if !im.add(line, book) {
+			im.writeBusy(w)
+			return
+		}
+	}
+
+	if atomic {
+		importCSVAtomic(w, r, im, all, allLines)
+		return
+	}
+	if !im.flush() {
+		im.writeBusy(w)
+		return
+	}
+	im.writeSummary(w)
+}
+
+// atomic 가져오기 저장 (실패 항목이 있으면 저장하지 않고 422)
+func importCSVAtomic(w http.ResponseWriter, r *http.Request, im *bookImporter, books []Book, lines []int) {
+	if len(im.errors) > 0 {
+		writeError(w, r, http.StatusUnprocessableEntity, "잘못된 행이 있어 전체 등록이 취소되었습니다", map[string]interface{}{
+			"imported": 0,
+			"failed":   len(im.errors),
+			"errors":   im.errors,
+		})
+		return
+	}
+	if len(books) == 0 {
+		im.writeSummary(w)
+		return
+	}
+
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	created, err := repo.CreateBatch(ctx, books)
+	if isRetryableWriteError(err) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusServiceUnavailable, "동시 처리 중인 쓰기 요청이 많습니다. 잠시 후 다시 시도하세요", nil)
+		return
+	}
+	var rowErr *batchRowError
+	if errors.As(err, &rowErr) && !errors.Is(err, context.DeadlineExceeded) {
+		requestLogger(r.Context()).Error("CSV 가져오기 DB 에러", "line", lines[rowErr.Index], "error", rowErr.Err)
+		status, reason := http.StatusUnprocessableEntity, "DB 저장 실패"
+		var dup *duplicateKeyError
+		if errors.As(rowErr.Err, &dup) {
+			status, reason = http.StatusConflict, dup.Error()
+		}
+		im.fail(lines[rowErr.Index], reason, nil)
+		writeError(w, r, status, "DB 저장 실패로 전체 등록이 취소되었습니다", map[string]interface{}{
+			"imported": 0,
+			"failed":   len(im.errors),
+			"errors":   im.errors,
+		})
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("CSV 가져오기 DB 에러", "error", err)
+		writeDBError(w, r, err, "CSV 가져오기 실패")
+		return
+	}
+
+	im.imported = len(created)
+	im.writeSummary(w)
+}
+
+// CSV 가져오기 본문 (text/csv 본문 또는 multipart의 file 필드, 형식이 다르면 415 응답 후 false)
+func csvImportBody(w http.ResponseWriter, r *http.Request) (io.Reader, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return r.Body, true
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "잘못된 multipart 요청입니다", nil)
+			return nil, false
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "잘못된 multipart 요청입니다", nil)
+				return nil, false
+			}
+			if part.FormName() == "file" {
+				return part, true
+			}
+		}
+		writeError(w, r, http.StatusBadRequest, "multipart 요청에 file 필드가 없습니다", nil)
+		return nil, false
+	}
+	writeError(w, r, http.StatusUnsupportedMediaType, "Content-Type은 text/csv 또는 multipart/form-data여야 합니다", nil)
+	return nil, false
+}
+
+// CSV 행을 책으로 변환 (year가 숫자가 아니면 필드 에러)
+func csvRecordBook(record []string, columns map[string]int) (Book, map[string]string) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	book := Book{Title: field("title"), Author: field("author"), ISBN: normalizeISBN(field("isbn"))}
+	year, err := strconv.Atoi(field("year"))
+	if err != nil {
+		return book, map[string]string{"year": "정수여야 합니다"}
+	}
+	book.Year, book.yearSet = year, true
+	return book, nil
+}
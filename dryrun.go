@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// 드라이런 요청 컨텍스트 키
+const dryRunKey contextKey = "dryRun"
+
+// 드라이런 요청 여부 (저장소는 쓰기 결과를 계산만 하고 반영하지 않음)
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
+
+// 드라이런 미들웨어 (?dry_run=true 또는 Dry-Run: true 헤더)
+//
+// 검증과 SQL 실행까지 평소와 같이 처리하되 MSSQL은 항상 롤백하는 트랜잭션 안에서, 메모리 저장소는 복사본에서 실행한다.
+// 성공 응답은 200으로 바꾸고 본문에 "dry_run": true를 넣는다.
+// 본문이 객체면 그 객체에 필드를 추가하고, 배열이거나 비어 있으면(204) {"dry_run": true, "result": ...}로 감싼다.
+// 실제로 만들어지지 않은 책을 가리키므로 Location 헤더는 보내지 않는다. 실패 응답은 그대로 전달한다.
+// MSSQL은 롤백해도 IDENTITY 값이 소비되므로 응답의 id는 실제 등록 시의 id와 다를 수 있다.
+func dryRunMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := r.URL.Query().Get("dry_run")
+		if raw == "" {
+			raw = r.Header.Get("Dry-Run")
+		}
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "dry_run은 true 또는 false여야 합니다", nil)
+			return
+		}
+		if !dryRun {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &dryRunRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), dryRunKey, true)))
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if rec.status < 200 || rec.status >= 300 {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		w.Header().Del("Location")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(dryRunBody(rec.body.Bytes()))
+	}
+}
+
+// 드라이런 응답 본문 (객체면 dry_run 필드 추가, 아니면 result로 감쌈)
+func dryRunBody(body []byte) []byte {
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("{")) && json.Valid(body) {
+		if bytes.Equal(body, []byte("{}")) {
+			return []byte(`{"dry_run":true}` + "\n")
+		}
+		return append(append([]byte(`{"dry_run":true,`), body[1:]...), '\n')
+	}
+
+	result := json.RawMessage("null")
+	if len(body) > 0 && json.Valid(body) {
+		result = body
+	}
+	data, _ := json.Marshal(struct {
+		DryRun bool            `json:"dry_run"`
+		Result json.RawMessage `json:"result"`
+	}{true, result})
+	return append(data, '\n')
+}
+
+// 드라이런 응답을 바꿔 쓰기 위해 상태 코드와 본문을 모아 두는 ResponseWriter
+type dryRunRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *dryRunRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *dryRunRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// 에러 응답 작성
+//
+// 기본 형식은 {"error": 메시지, ...extra}이다.
+// 클라이언트가 Accept: application/problem+json을 보내거나 PROBLEM_JSON=true이면
+// RFC 7807 형식(type, title, status, detail, instance)으로 응답하고 extra는 확장 필드로 덧붙인다.
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string, extra map[string]interface{}) {
+	if wantsProblemJSON(r) {
+		body := map[string]interface{}{}
+		for k, v := range extra {
+			body[k] = v
+		}
+		body["type"] = "about:blank"
+		body["title"] = http.StatusText(status)
+		body["status"] = status
+		body["detail"] = message
+		body["instance"] = externalPath(r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+
+	body := map[string]interface{}{"error": message}
+	for k, v := range extra {
+		body[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// DB 에러 응답 작성 (제한 시간 초과는 504, 그 외는 500)
+func writeDBError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, r, http.StatusGatewayTimeout, "database timeout", nil)
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, message, nil)
+}
+
+// 중복 키 응답 (409, 충돌한 필드를 알면 field로 포함)
+// 중복 키 에러가 아니면 아무것도 쓰지 않고 false를 반환한다.
+func writeDuplicateError(w http.ResponseWriter, r *http.Request, err error, extra map[string]interface{}) bool {
+	var dup *duplicateKeyError
+	if !errors.As(err, &dup) {
+		return false
+	}
+	body := map[string]interface{}{}
+	for k, v := range extra {
+		body[k] = v
+	}
+	if dup.Field != "" {
+		body["field"] = dup.Field
+	}
+	writeError(w, r, http.StatusConflict, dup.Error(), body)
+	return true
+}
+
+// problem+json 형식 응답 여부
+// PROBLEM_JSON을 켜기 전에 problem_json 기능 플래그로 일부 클라이언트에만 먼저 적용할 수 있다.
+func wantsProblemJSON(r *http.Request) bool {
+	if appConfig != nil && appConfig.ProblemJSON {
+		return true
+	}
+	if hasFeature(r, "problem_json") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/problem+json")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// 책 ETag (직렬화한 책의 SHA-256, updated_at/version이 바뀌면 함께 바뀜)
+func bookETag(book Book) string {
+	data, _ := json.Marshal(book)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// If-Match/If-None-Match 헤더 값과 ETag 비교 ("*" 또는 쉼표로 구분된 목록)
+// weak이면 W/ 접두사를 무시하고 비교한다 (If-None-Match용).
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// If-Match 조건 확인 (헤더가 없으면 통과)
+// 현재 책을 조회해 ETag가 다르면 412, 책이 없으면 404 응답을 작성하고 false를 반환한다.
+func checkIfMatch(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) (Book, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return Book{}, true
+	}
+
+	current, err := repo.Get(ctx, id, false)
+	if err == errBookNotFound {
+		writeError(w, r, http.StatusNotFound, "책을 찾을 수 없습니다", nil)
+		return Book{}, false
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("조회 에러", "error", err)
+		writeDBError(w, r, err, "책 정보 조회 실패")
+		return Book{}, false
+	}
+	if !etagMatches(ifMatch, bookETag(current), false) {
+		writeError(w, r, http.StatusPreconditionFailed, "책이 그 사이 변경되었습니다 (If-Match 불일치)", nil)
+		return Book{}, false
+	}
+	return current, true
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CSV 내보내기 헤더 행
+var exportCSVHeader = []string{"id", "title", "author", "year", "regdate", "isbn"}
+
+// CSV 내보내기 요청 여부 (REQUEST_TIMEOUT 대신 EXPORT_TIMEOUT 적용, 경로 접두사와 무관)
+func isExportRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/books/export.csv")
+}
+
+// 내보내기 컨텍스트 (DB_QUERY_TIMEOUT 대신 EXPORT_TIMEOUT, 0이면 클라이언트 연결이 끊길 때까지)
+func exportContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if appConfig == nil || appConfig.ExportTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, appConfig.ExportTimeout)
+}
+
+// 책 목록 CSV 내보내기 (GetBooks와 같은 필터/정렬, 페이지 제한 없음)
+// 커서에서 읽은 행을 바로 써서 테이블이 커도 메모리 사용량이 일정하다.
+// 조회 전체에 EXPORT_TIMEOUT을 적용한다 (DB_QUERY_TIMEOUT, REQUEST_TIMEOUT은 적용하지 않음).
+// 첫 행을 쓰기 전의 에러는 에러 응답으로 알린다. 응답을 쓰기 시작한 뒤의 에러는 상태 코드를 바꿀 수 없으므로
+// 연결을 끊어(http.ErrAbortHandler) 클라이언트가 잘린 파일을 정상 완료로 받지 않게 한다.
+func ExportBooksCSV(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseBookFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter.Tenant = tenantFromContext(r.Context())
+
+	sort, err := parseBookSort(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := exportContext(r.Context())
+	defer cancel()
+
+	cw := csv.NewWriter(w)
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="books.csv"`)
+		return cw.Write(exportCSVHeader)
+	}
+
+	err = repo.Each(ctx, filter, sort, pagination{}, func(book Book) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return cw.Write([]string{book.ID, book.Title, book.Author, strconv.Itoa(book.Year), book.Regdate, book.ISBN})
+	})
+	if err != nil && !started {
+		requestLogger(r.Context()).Error("내보내기 조회 에러", "error", err)
+		writeDBError(w, r, err, "책 목록 내보내기 실패")
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("내보내기 스트리밍 에러 (연결 종료)", "error", err)
+		cw.Flush()
+		panic(http.ErrAbortHandler)
+	}
+	if !started {
+		start()
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		requestLogger(r.Context()).Error("CSV 쓰기 에러", "error", err)
+	}
+}
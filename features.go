@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// 컨텍스트 키 타입 (다른 패키지의 키와 충돌 방지)
+type contextKey string
+
+// 요청별 기능 플래그 컨텍스트 키
+const featureFlagsKey contextKey = "featureFlags"
+
+// 요청별 기능 플래그 미들웨어
+// X-Feature-Flags 헤더(쉼표 구분) 중 서버 허용 목록(FEATURE_FLAGS)에 있는 플래그만 컨텍스트에 저장하고,
+// 적용된 플래그를 X-Feature-Flags-Applied 헤더로 알려준다.
+func featureFlagsMiddleware(allowed map[string]struct{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("X-Feature-Flags")
+			if header == "" || len(allowed) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			flags := map[string]struct{}{}
+			var applied []string
+			for _, flag := range strings.Split(header, ",") {
+				flag = strings.ToLower(strings.TrimSpace(flag))
+				if _, ok := allowed[flag]; !ok {
+					continue
+				}
+				if _, dup := flags[flag]; !dup {
+					flags[flag] = struct{}{}
+					applied = append(applied, flag)
+				}
+			}
+
+			if len(applied) > 0 {
+				w.Header().Set("X-Feature-Flags-Applied", strings.Join(applied, ","))
+				r = r.WithContext(context.WithValue(r.Context(), featureFlagsKey, flags))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// 요청에 기능 플래그가 켜져 있는지 여부 (핸들러에서 새 동작으로 분기할 때 사용, 예: wantsProblemJSON)
+func hasFeature(r *http.Request, name string) bool {
+	flags, _ := r.Context().Value(featureFlagsKey).(map[string]struct{})
+	_, ok := flags[name]
+	return ok
+}
+
+// 쉼표 구분 문자열을 집합으로 변환 (소문자, 공백 제거)
+func parseStringSet(value string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			set[item] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProblemJSONFeatureFlag(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"FEATURE_FLAGS": "problem_json"})
+
+	tests := []struct {
+		name        string
+		flags       string
+		contentType string
+		applied     string
+	}{
+		{"플래그 없음", "", "application/json", ""},
+		{"problem_json", "Problem_JSON, unknown", "application/problem+json", "problem_json"},
+		{"허용 목록에 없는 플래그", "unknown", "application/json", ""},
+	}
+	for _, tt := range tests {
+		header := http.Header{"X-Api-Key": {testAPIKey}}
+		if tt.flags != "" {
+			header.Set("X-Feature-Flags", tt.flags)
+		}
+		rec := doRequest(t, h, "GET", "/v1/books/999", "", header)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: 상태 코드 = %d, 원하는 값 404", tt.name, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, 원하는 값 %q", tt.name, got, tt.contentType)
+		}
+		if got := rec.Header().Get("X-Feature-Flags-Applied"); got != tt.applied {
+			t.Errorf("%s: X-Feature-Flags-Applied = %q, 원하는 값 %q", tt.name, got, tt.applied)
+		}
+	}
+}
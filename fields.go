@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ?fields=로 요청할 수 있는 필드 (SELECT 컬럼 목록에 그대로 들어가므로 이 목록 외의 값은 허용하지 않음)
+var selectableFields = strings.Split(bookColumns, ", ")
+
+// fields 파라미터 파싱 (없으면 nil, 알 수 없는 필드면 에러)
+// 결과는 중복을 빼고 bookColumns 순서로 정렬한다.
+func parseFieldsParam(r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	requested := parseStringSet(raw)
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("fields에는 필드를 하나 이상 지정해야 합니다")
+	}
+	for name := range requested {
+		if !slices.Contains(selectableFields, name) {
+			return nil, fmt.Errorf("알 수 없는 필드입니다: %s (가능: %s)", name, bookColumns)
+		}
+	}
+
+	var fields []string
+	for _, name := range selectableFields {
+		if _, ok := requested[name]; ok {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// 필드 이름으로 책 값 조회
+func (b Book) fieldValue(name string) interface{} {
+	switch name {
+	case "id":
+		return b.ID
+	case "title":
+		return b.Title
+	case "author":
+		return b.Author
+	case "year":
+		return b.Year
+	case "isbn":
+		return b.ISBN
+	case "regdate":
+		return b.Regdate
+	case "updated_at":
+		return b.UpdatedAt
+	case "version":
+		return b.Version
+	}
+	return nil
+}
+
+// ?fields=로 일부 필드만 요청한 책 응답 (요청한 필드와 include 계산 필드만 출력)
+type partialBook struct {
+	view   bookView
+	fields []string
+}
+
+// 출력할 이름과 값 (bookColumns 순서, 계산 필드는 뒤에)
+func (p partialBook) pairs() ([]string, []interface{}) {
+	names := append([]string(nil), p.fields...)
+	values := make([]interface{}, 0, len(names)+2)
+	for _, name := range p.fields {
+		values = append(values, p.view.fieldValue(name))
+	}
+	if p.view.Age != nil {
+		names = append(names, "age")
+		values = append(values, *p.view.Age)
+	}
+	if p.view.Decade != nil {
+		names = append(names, "decade")
+		values = append(values, *p.view.Decade)
+	}
+	return names, values
+}
+
+func (p partialBook) MarshalJSON() ([]byte, error) {
+	names, values := p.pairs()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`"` + name + `":`)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p partialBook) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	names, values := p.pairs()
+	for i, name := range names {
+		if err := e.EncodeElement(values[i], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// authors 필터에 허용되는 최대 저자 수
+const maxAuthorsFilter = 20
+
+// 페이지 크기 기본값 및 허용 범위
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+
+	// ?all=true로 한 번에 받을 수 있는 최대 건수 (넘으면 Link 헤더의 next로 이어서 조회)
+	maxAllPageLimit = 10000
+)
+
+// 목록 페이지 범위 (limit/offset, Limit이 0이면 offset 이후 전체)
+type pagination struct {
+	Limit  int
+	Offset int
+
+	// 키셋 페이지 (?after=로 요청, id 오름차순으로 AfterID보다 큰 id부터 - AfterID가 0이면 처음부터)
+	Keyset  bool
+	AfterID int
+
+	// ?all=true 전체 조회 (limit 대신 maxAllPageLimit까지)
+	All bool
+}
+
+// limit/offset 또는 after 파라미터 파싱 (없으면 기본값, 형식이나 범위가 잘못되면 에러)
+// after를 보내면 (빈 값이면 첫 페이지) offset 대신 키셋 페이지로 조회한다.
+// all=true를 보내면 limit 없이 최대 maxAllPageLimit건까지 한 번에 조회한다 (offset과는 함께 사용 가능).
+func parsePagination(r *http.Request) (pagination, error) {
+	page := pagination{Limit: defaultPageLimit}
+	query := r.URL.Query()
+
+	if raw := query.Get("all"); raw != "" {
+		all, err := strconv.ParseBool(raw)
+		if err != nil {
+			return page, fmt.Errorf("all은 true 또는 false여야 합니다")
+		}
+		if all {
+			if query.Has("limit") || query.Has("after") {
+				return page, fmt.Errorf("all은 limit, after와 함께 사용할 수 없습니다")
+			}
+			page.All, page.Limit = true, maxAllPageLimit
+		}
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, fmt.Errorf("limit은 1 이상 %d 이하의 정수여야 합니다", maxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("offset은 0 이상의 정수여야 합니다")
+		}
+		page.Offset = offset
+	}
+
+	if query.Has("after") {
+		if query.Has("offset") {
+			return page, fmt.Errorf("after와 offset은 함께 사용할 수 없습니다")
+		}
+		afterID, err := decodeBookCursor(query.Get("after"))
+		if err != nil {
+			return page, err
+		}
+		page.Keyset, page.AfterID = true, afterID
+	}
+
+	return page, nil
+}
+
+// 다음 페이지 커서 (마지막 책 id를 base64url로 인코딩, 클라이언트는 after로 그대로 다시 보냄)
+func encodeBookCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// after 커서 디코딩 (빈 값이면 0 - 첫 페이지)
+func decodeBookCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("잘못된 after 커서입니다")
+	}
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("잘못된 after 커서입니다")
+	}
+	return id, nil
+}
+
+// 키셋 페이지 조건을 WHERE 절에 추가 (filter.where() 결과에 id > ? 조건을 덧붙임)
+func (p pagination) where(where string, args []interface{}) (string, []interface{}) {
+	if p.AfterID == 0 {
+		return where, args
+	}
+	if where == "" {
+		return " WHERE id > ?", []interface{}{p.AfterID}
+	}
+	return where + " AND id > ?", append(args, p.AfterID)
+}
+
+// ORDER BY 뒤에 붙일 OFFSET/FETCH 절과 바인딩 파라미터
+func (p pagination) clause() (string, []interface{}) {
+	if p.Limit == 0 {
+		return " OFFSET ? ROWS", []interface{}{p.Offset}
+	}
+	return " OFFSET ? ROWS FETCH NEXT ? ROWS ONLY", []interface{}{p.Offset, p.Limit}
+}
+
+// 페이지 이동 Link 헤더 값 (RFC 8288, first/prev/next/last)
+// 요청 경로와 쿼리 파라미터를 그대로 두고 offset(limit)만 바꾸므로 필터와 정렬이 유지된다.
+// 첫 페이지에서는 prev, 마지막 페이지에서는 next를 넣지 않는다.
+func (p pagination) linkHeader(r *http.Request, total int) string {
+	if p.Limit == 0 {
+		return ""
+	}
+	link := func(offset int, rel string) string {
+		query := r.URL.Query()
+		if !p.All {
+			query.Set("limit", strconv.Itoa(p.Limit))
+		}
+		query.Set("offset", strconv.Itoa(offset))
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, externalPath(r.URL.Path), query.Encode(), rel)
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / p.Limit * p.Limit
+	}
+	links := []string{link(0, "first")}
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, link(prev, "prev"))
+	}
+	if p.Offset+p.Limit < total {
+		links = append(links, link(p.Offset+p.Limit, "next"))
+	}
+	links = append(links, link(last, "last"))
+	return strings.Join(links, ", ")
+}
+
+// 키셋 페이지 Link 헤더 값 (first, 다음 페이지가 있으면 next)
+func (p pagination) keysetLinkHeader(r *http.Request, nextCursor string) string {
+	link := func(cursor, rel string) string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(p.Limit))
+		query.Set("after", cursor)
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, externalPath(r.URL.Path), query.Encode(), rel)
+	}
+
+	links := []string{link("", "first")}
+	if nextCursor != "" {
+		links = append(links, link(nextCursor, "next"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// 정렬 가능한 컬럼 (ORDER BY에 그대로 들어가므로 이 목록 외의 값은 허용하지 않음)
+var sortableColumns = map[string]struct{}{
+	"id": {}, "title": {}, "author": {}, "year": {}, "regdate": {},
+}
+
+// 목록 정렬 (sort/order)
+type bookSort struct {
+	Column string
+	Desc   bool
+}
+
+// sort/order 파라미터 파싱 (없으면 id 오름차순)
+func parseBookSort(r *http.Request) (bookSort, error) {
+	sort := bookSort{Column: "id"}
+	query := r.URL.Query()
+
+	if raw := strings.ToLower(strings.TrimSpace(query.Get("sort"))); raw != "" {
+		if _, ok := sortableColumns[raw]; !ok {
+			return sort, fmt.Errorf("sort는 id, title, author, year, regdate 중 하나여야 합니다")
+		}
+		sort.Column = raw
+	}
+
+	switch strings.ToLower(strings.TrimSpace(query.Get("order"))) {
+	case "", "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		return sort, fmt.Errorf("order는 asc 또는 desc여야 합니다")
+	}
+
+	return sort, nil
+}
+
+// ORDER BY 절 (페이지가 흔들리지 않도록 id를 보조 정렬 키로 추가)
+func (s bookSort) orderBy() string {
+	direction := " ASC"
+	if s.Desc {
+		direction = " DESC"
+	}
+	clause := " ORDER BY " + s.Column + direction
+	if s.Column != "id" {
+		clause += ", id ASC"
+	}
+	return clause
+}
+
+// include_deleted 파라미터 파싱 (SOFT_DELETE 사용 시 삭제된 책 포함 여부)
+func parseIncludeDeleted(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("include_deleted")
+	if raw == "" {
+		return false, nil
+	}
+	includeDeleted, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("include_deleted는 true 또는 false여야 합니다")
+	}
+	return includeDeleted, nil
+}
+
+// 목록 조회 필터
+type bookFilter struct {
+	Authors []string
+	Author  string // 저자 부분 일치 (대소문자 무시)
+	Title   string // 제목 부분 일치 (대소문자 무시)
+	Year    int    // 출판 연도 일치 (0이면 조건 없음)
+	Tenant  string // 멀티 테넌시 사용 시 요청 테넌트 (쿼리 파라미터가 아닌 API 키에서 결정)
+
+	// 저자 전체 일치 (대소문자 무시, 쿼리 파라미터가 아닌 /authors/{author}/books 경로에서 설정)
+	AuthorExact string
+
+	// 출판 연도 범위 (양 끝 포함, 0이면 조건 없음)
+	YearFrom int
+	YearTo   int
+
+	IncludeDeleted bool // SOFT_DELETE 사용 시 삭제된 책도 포함
+
+	// 목록 조회 시 SELECT할 컬럼 (비어 있으면 전체, selectableFields 안의 값만 허용 - 건수 조회에는 영향 없음)
+	Fields []string
+}
+
+// 쿼리 파라미터에서 필터 생성
+// 빈 값으로 보낸 파라미터는 조건에 넣지 않는다.
+func parseBookFilter(r *http.Request) (bookFilter, error) {
+	var filter bookFilter
+	query := r.URL.Query()
+
+	includeDeleted, err := parseIncludeDeleted(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.IncludeDeleted = includeDeleted
+
+	filter.Author = strings.TrimSpace(query.Get("author"))
+	filter.Title = strings.TrimSpace(query.Get("title"))
+
+	if raw := strings.TrimSpace(query.Get("year")); raw != "" {
+		year, err := strconv.Atoi(raw)
+		if err != nil || year <= 0 {
+			return filter, fmt.Errorf("year는 양의 정수여야 합니다")
+		}
+		filter.Year = year
+	}
+
+	// 출판 연도 범위 (year_from/year_to, 한쪽만 보내면 열린 범위)
+	if filter.YearFrom, filter.YearTo, err = parseYearRange(r, "year_from", "year_to"); err != nil {
+		return filter, err
+	}
+
+	if raw := query.Get("authors"); raw != "" {
+		for _, author := range strings.Split(raw, ",") {
+			if author = strings.TrimSpace(author); author != "" {
+				filter.Authors = append(filter.Authors, author)
+			}
+		}
+		if len(filter.Authors) > maxAuthorsFilter {
+			return filter, fmt.Errorf("authors는 최대 %d명까지 지정할 수 있습니다", maxAuthorsFilter)
+		}
+	}
+
+	return filter, nil
+}
+
+// WHERE 절과 바인딩 파라미터 생성
+func (f bookFilter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(f.Authors) > 0 {
+		placeholders := make([]string, len(f.Authors))
+		for i, author := range f.Authors {
+			placeholders[i] = "?"
+			args = append(args, author)
+		}
+		conditions = append(conditions, "author IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	if f.Author != "" {
+		conditions = append(conditions, `LOWER(author) LIKE LOWER(?) ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(f.Author)+"%")
+	}
+
+	if f.AuthorExact != "" {
+		conditions = append(conditions, "LOWER(author) = LOWER(?)")
+		args = append(args, f.AuthorExact)
+	}
+
+	if f.Title != "" {
+		conditions = append(conditions, `LOWER(title) LIKE LOWER(?) ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(f.Title)+"%")
+	}
+
+	if f.Year != 0 {
+		conditions = append(conditions, "year = ?")
+		args = append(args, f.Year)
+	}
+
+	switch {
+	case f.YearFrom != 0 && f.YearTo != 0:
+		conditions = append(conditions, "year BETWEEN ? AND ?")
+		args = append(args, f.YearFrom, f.YearTo)
+	case f.YearFrom != 0:
+		conditions = append(conditions, "year >= ?")
+		args = append(args, f.YearFrom)
+	case f.YearTo != 0:
+		conditions = append(conditions, "year <= ?")
+		args = append(args, f.YearTo)
+	}
+
+	if f.Tenant != "" {
+		conditions = append(conditions, "tenant_id = ?")
+		args = append(args, f.Tenant)
+	}
+
+	if softDeleteEnabled() && !f.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// 출판 연도 범위 파라미터 파싱 (fromName/toName 파라미터, 없으면 0)
+func parseYearRange(r *http.Request, fromName, toName string) (int, int, error) {
+	query := r.URL.Query()
+	bounds := [2]int{}
+	for i, name := range []string{fromName, toName} {
+		raw := strings.TrimSpace(query.Get(name))
+		if raw == "" {
+			continue
+		}
+		year, err := strconv.Atoi(raw)
+		if err != nil || year <= 0 {
+			return 0, 0, fmt.Errorf("%s는 양의 정수여야 합니다", name)
+		}
+		bounds[i] = year
+	}
+	if bounds[0] != 0 && bounds[1] != 0 && bounds[0] > bounds[1] {
+		return 0, 0, fmt.Errorf("%s은 %s보다 클 수 없습니다", fromName, toName)
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// LIKE 패턴 특수문자 이스케이프 (입력값을 문자 그대로 검색)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBookFilterWhere(t *testing.T) {
+	appConfig = &Config{}
+	tests := []struct {
+		query     string
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{"", "", nil},
+		{"author=&title=+&year=", "", nil},
+		{"author=한강", ` WHERE LOWER(author) LIKE LOWER(?) ESCAPE '\'`, []interface{}{"%한강%"}},
+		{"title=50%25", ` WHERE LOWER(title) LIKE LOWER(?) ESCAPE '\'`, []interface{}{`%50\%%`}},
+		{"year=2014", " WHERE year = ?", []interface{}{2014}},
+		{
+			"author=한강&title=소년&year=2014",
+			` WHERE LOWER(author) LIKE LOWER(?) ESCAPE '\' AND LOWER(title) LIKE LOWER(?) ESCAPE '\' AND year = ?`,
+			[]interface{}{"%한강%", "%소년%", 2014},
+		},
+		{"year_from=2000&year_to=2010", " WHERE year BETWEEN ? AND ?", []interface{}{2000, 2010}},
+		{"year_to=2010", " WHERE year <= ?", []interface{}{2010}},
+		{"authors=한강,+,박경리", " WHERE author IN (?, ?)", []interface{}{"한강", "박경리"}},
+	}
+	for _, tt := range tests {
+		filter, err := parseBookFilter(httptest.NewRequest("GET", "/books?"+tt.query, nil))
+		if err != nil {
+			t.Errorf("parseBookFilter(%q) 에러: %v", tt.query, err)
+			continue
+		}
+		where, args := filter.where()
+		if where != tt.wantWhere || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%q: where() = %q %v, 원하는 값 %q %v", tt.query, where, args, tt.wantWhere, tt.wantArgs)
+		}
+	}
+}
+
+func TestParseBookFilterErrors(t *testing.T) {
+	for _, query := range []string{"year=abc", "year=-1", "year_from=2010&year_to=2000", "year_from=x"} {
+		if _, err := parseBookFilter(httptest.NewRequest("GET", "/books?"+query, nil)); err == nil {
+			t.Errorf("parseBookFilter(%q)가 에러를 반환하지 않았습니다", query)
+		}
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    pagination
+		wantErr bool
+	}{
+		{"", pagination{Limit: defaultPageLimit}, false},
+		{"limit=5&offset=10", pagination{Limit: 5, Offset: 10}, false},
+		{"after=", pagination{Limit: defaultPageLimit, Keyset: true}, false},
+		{"after=" + encodeBookCursor("42"), pagination{Limit: defaultPageLimit, Keyset: true, AfterID: 42}, false},
+		{"limit=0", pagination{}, true},
+		{"offset=-1", pagination{}, true},
+		{"after=" + encodeBookCursor("1") + "&offset=0", pagination{}, true},
+		{"after=!!", pagination{}, true},
+		{"all=true", pagination{Limit: maxAllPageLimit, All: true}, false},
+		{"all=true&offset=20", pagination{Limit: maxAllPageLimit, Offset: 20, All: true}, false},
+		{"all=false", pagination{Limit: defaultPageLimit}, false},
+		{"all=yes", pagination{}, true},
+		{"all=true&limit=10", pagination{}, true},
+		{"all=true&after=", pagination{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePagination(httptest.NewRequest("GET", "/books?"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePagination(%q) 에러 = %v, 에러 기대 %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parsePagination(%q) = %+v, 원하는 값 %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseBookSort(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"", " ORDER BY id ASC", false},
+		{"sort=year&order=desc", " ORDER BY year DESC, id ASC", false},
+		{"sort=TITLE", " ORDER BY title ASC, id ASC", false},
+		{"sort=isbn", "", true},
+		{"order=up", "", true},
+	}
+	for _, tt := range tests {
+		sort, err := parseBookSort(httptest.NewRequest("GET", "/books?"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBookSort(%q) 에러 = %v, 에러 기대 %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && sort.orderBy() != tt.want {
+			t.Errorf("parseBookSort(%q).orderBy() = %q, 원하는 값 %q", tt.query, sort.orderBy(), tt.want)
+		}
+	}
+}
+
+func TestGetBooksFilterResults(t *testing.T) {
+	h := newTestHandler(t, nil)
+	createTestBook(t, h, `{"title":"채식주의자","author":"한강","year":2007}`)
+	createTestBook(t, h, `{"title":"소년이 온다","author":"한강","year":2014}`)
+	createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	createTestBook(t, h, `{"title":"Pachinko","author":"Min Jin Lee","year":2017}`)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"채식주의자", "소년이 온다", "토지", "Pachinko"}},
+		{"author=&title=&year=", []string{"채식주의자", "소년이 온다", "토지", "Pachinko"}},
+		{"author=한강", []string{"채식주의자", "소년이 온다"}},
+		{"author=한강&year=2014", []string{"소년이 온다"}},
+		{"author=박경리&year=2014", []string{}},
+		{"title=pACHIN", []string{"Pachinko"}},
+		{"author=min+jin", []string{"Pachinko"}},
+		{"year_from=2000&year_to=2015", []string{"채식주의자", "소년이 온다"}},
+		{"sort=year&order=desc&limit=2", []string{"Pachinko", "소년이 온다"}},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, h, "GET", "/v1/books?"+tt.query, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%q: 상태 코드 = %d (%s)", tt.query, rec.Code, rec.Body.String())
+			continue
+		}
+		var books []Book
+		decodeResponse(t, rec, &books)
+		titles := []string{}
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		if !reflect.DeepEqual(titles, tt.want) {
+			t.Errorf("%q: 제목 = %v, 원하는 값 %v", tt.query, titles, tt.want)
+		}
+	}
+
+	rec := doRequest(t, h, "GET", "/v1/books?year=abc", "", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("잘못된 year 상태 코드 = %d, 원하는 값 400", rec.Code)
+	}
+}
+
+func TestGetBooksAll(t *testing.T) {
+	h := newTestHandler(t, nil)
+	for i := 0; i < defaultPageLimit+5; i++ {
+		createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	}
+
+	for _, path := range []string{"/v1/books", "/v1/authors/박경리/books"} {
+		var books []Book
+		decodeResponse(t, doRequest(t, h, "GET", path, "", nil), &books)
+		if len(books) != defaultPageLimit {
+			t.Errorf("%s 기본 페이지 길이 = %d, 원하는 값 %d", path, len(books), defaultPageLimit)
+		}
+
+		rec := doRequest(t, h, "GET", path+"?all=true", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s?all=true 상태 코드 = %d (%s)", path, rec.Code, rec.Body.String())
+		}
+		decodeResponse(t, rec, &books)
+		if len(books) != defaultPageLimit+5 {
+			t.Errorf("%s?all=true 길이 = %d, 원하는 값 %d", path, len(books), defaultPageLimit+5)
+		}
+	}
+
+	rec := doRequest(t, h, "GET", "/v1/books?all=true&limit=10", "", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("all과 limit을 함께 보낸 상태 코드 = %d, 원하는 값 400", rec.Code)
+	}
+}
+
+// all=true 조회가 상한에 걸리면 next 링크도 all=true로 이어서 조회
+func TestAllLinkHeader(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/books?all=true", nil)
+	page, err := parsePagination(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := page.linkHeader(req, maxAllPageLimit+1)
+	want := `</v1/books?all=true&offset=10000>; rel="next"`
+	if !strings.Contains(link, want) || strings.Contains(link, "limit=") {
+		t.Errorf("Link = %s, %s가 있고 limit이 없어야 함", link, want)
+	}
+	if _, err := parsePagination(httptest.NewRequest("GET", "/v1/books?all=true&offset=10000", nil)); err != nil {
+		t.Errorf("next 링크 쿼리 파싱 에러: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// 프로세스 시작 시각 (가동 시간 계산용)
+var startTime = time.Now()
+
+// 구성 요소 상태 (up, down)
+type componentHealth struct {
+	Status    string `json:"status"`
+	LatencyMS *int64 `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	// DB 서버 현재 시각과 앱 시각과의 차이 (DB 시각 - 앱 시각, 밀리초)
+	ServerTime  string `json:"server_time,omitempty"`
+	ClockSkewMS *int64 `json:"clock_skew_ms,omitempty"`
+}
+
+// 시각 비교용 출력 형식 (밀리초까지)
+const healthTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// 구성 요소별 상세 상태 조회 (대시보드용, 인증 불필요)
+//
+// 응답 예: {"db":{"status":"up","latency_ms":3,"server_time":"...","clock_skew_ms":-12},"app_time":"...","uptime_s":1234,"version":"1.2.3"}
+// 앱과 DB의 시계 차이를 확인할 수 있도록 DB 서버 시각(GETDATE()와 같은 시각에 시간대 오프셋이 붙은 SYSDATETIMEOFFSET())과
+// 앱 시각, 그 차이를 함께 보여준다. 차이는 왕복 시간의 절반을 빼 네트워크 지연을 보정한 값이다.
+// 모든 구성 요소가 up이면 200, 하나라도 down이면 503이며 어느 경우든 구성 요소별 상태를 본문에 담는다.
+// 메모리 저장소로 실행 중이면 db는 ping 없이 up으로 표시한다.
+func HealthDetail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	dbHealth := componentHealth{Status: "up"}
+	if db != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readyPingTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := db.PingContext(ctx)
+		latency := time.Since(start).Milliseconds()
+		dbHealth.LatencyMS = &latency
+		if err != nil {
+			dbHealth.Status = "down"
+			dbHealth.Error = redactSecret(err.Error(), appConfig.DBPassword)
+		} else {
+			var serverTime time.Time
+			sent := time.Now()
+			err := db.QueryRowContext(ctx, "SELECT SYSDATETIMEOFFSET()").Scan(&serverTime)
+			received := time.Now()
+			if err != nil {
+				requestLogger(r.Context()).Warn("DB 서버 시각 조회 에러", "error", err)
+			} else {
+				appTime := sent.Add(received.Sub(sent) / 2)
+				skew := serverTime.Sub(appTime).Milliseconds()
+				dbHealth.ServerTime = serverTime.Format(healthTimeFormat)
+				dbHealth.ClockSkewMS = &skew
+			}
+		}
+	}
+
+	if dbHealth.Status != "up" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"db":       dbHealth,
+		"app_time": time.Now().UTC().Format(healthTimeFormat),
+		"uptime_s": int64(time.Since(startTime).Seconds()),
+		"version":  Version,
+	})
+}
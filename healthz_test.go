@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealthDetailMemory(t *testing.T) {
+	h := newTestHandler(t, nil)
+	rec := doRequest(t, h, "GET", "/healthz/detail", "", http.Header{})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d, 원하는 값 200", rec.Code)
+	}
+	var body struct {
+		DB      map[string]interface{} `json:"db"`
+		AppTime string                 `json:"app_time"`
+	}
+	decodeResponse(t, rec, &body)
+	appTime, err := time.Parse(healthTimeFormat, body.AppTime)
+	if err != nil || time.Since(appTime).Abs() > time.Minute {
+		t.Errorf("app_time = %q", body.AppTime)
+	}
+	// 메모리 저장소는 DB 서버 시각이 없음
+	if _, ok := body.DB["server_time"]; ok {
+		t.Errorf("db = %v, server_time이 없어야 함", body.DB)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Idempotency-Key 최대 길이
+const maxIdempotencyKeyLength = 255
+
+// 재현 시 저장된 응답에서 그대로 돌려줄 헤더
+var idempotentReplayHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency-Key별 저장 항목
+type idempotencyEntry struct {
+	bodyHash [32]byte
+	done     bool // false면 처리 중
+	status   int
+	header   http.Header
+	body     []byte
+	expires  time.Time
+}
+
+// Idempotency-Key 미들웨어 (POST 재시도 시 중복 생성 방지)
+//
+// 같은 API 키로 같은 Idempotency-Key를 다시 보내면 핸들러를 실행하지 않고 처음 응답(상태 코드, 본문)을 재현한다.
+// 키는 같은데 요청 본문이 다르면 409, 첫 요청이 아직 처리 중이면 409로 응답한다.
+// 5xx 응답은 저장하지 않으므로 같은 키로 다시 시도할 수 있다.
+// 키는 프로세스 메모리에 ttl 동안 보관하므로 인스턴스가 여러 개면 같은 인스턴스로 재시도해야 재현된다.
+// ttl이 0이면 사용하지 않는다.
+// 저장소는 미들웨어 인스턴스 하나에 하나이므로 이 미들웨어로 감싼 라우트(/v1, 레거시 경로)는 키를 공유한다.
+func idempotencyMiddleware(ttl time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	if ttl <= 0 {
+		return func(next http.HandlerFunc) http.HandlerFunc { return next }
+	}
+
+	var mu sync.Mutex
+	entries := map[[32]byte]*idempotencyEntry{}
+
+	// 만료된 항목 정리
+	go func() {
+		for range time.Tick(time.Minute) {
+			now := time.Now()
+			mu.Lock()
+			for key, e := range entries {
+				if e.done && now.After(e.expires) {
+					delete(entries, key)
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// 드라이런은 아무것도 만들지 않으므로 저장하거나 재현하지 않음
+			idempotencyKey := r.Header.Get("Idempotency-Key")
+			if idempotencyKey == "" || isDryRun(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				writeError(w, r, http.StatusBadRequest, fmt.Sprintf("Idempotency-Key는 최대 %d자까지 사용할 수 있습니다", maxIdempotencyKeyLength), nil)
+				return
+			}
+
+			// 본문을 비교해야 하므로 먼저 읽고 핸들러가 다시 읽을 수 있게 되돌려 둠
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, appConfig.MaxBodyBytes))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("요청 본문은 최대 %d바이트까지 보낼 수 있습니다", tooLarge.Limit), nil)
+					return
+				}
+				writeError(w, r, http.StatusBadRequest, "요청 본문을 읽을 수 없습니다", nil)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// 클라이언트(API 키, JWT sub)별로 구분 (다른 클라이언트가 같은 키를 써도 섞이지 않음)
+			key := sha256.Sum256([]byte(clientID(r) + "\x00" + idempotencyKey))
+			bodyHash := sha256.Sum256(body)
+
+			mu.Lock()
+			entry, ok := entries[key]
+			if ok && entry.done && time.Now().After(entry.expires) {
+				ok = false
+			}
+			if !ok {
+				entry = &idempotencyEntry{bodyHash: bodyHash}
+				entries[key] = entry
+			}
+			mu.Unlock()
+
+			if ok {
+				switch {
+				case entry.bodyHash != bodyHash:
+					writeError(w, r, http.StatusConflict, "같은 Idempotency-Key가 다른 요청 본문으로 사용되었습니다", nil)
+				case !entry.done:
+					writeError(w, r, http.StatusConflict, "같은 Idempotency-Key의 요청이 아직 처리 중입니다", nil)
+				default:
+					for name, values := range entry.header {
+						w.Header()[name] = values
+					}
+					w.Header().Set("Idempotent-Replayed", "true")
+					w.WriteHeader(entry.status)
+					w.Write(entry.body)
+				}
+				return
+			}
+
+			rec := &idempotencyRecorder{ResponseWriter: w}
+			completed := false
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				if !completed || rec.status >= http.StatusInternalServerError {
+					delete(entries, key)
+					return
+				}
+				entry.done = true
+				entry.status = rec.status
+				entry.header = http.Header{}
+				for _, name := range idempotentReplayHeaders {
+					if values := w.Header().Values(name); len(values) > 0 {
+						entry.header[name] = values
+					}
+				}
+				entry.body = rec.body.Bytes()
+				entry.expires = time.Now().Add(ttl)
+			}()
+
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			completed = true
+		}
+	}
+}
+
+// 응답을 그대로 내보내면서 상태 코드와 본문을 기록하는 ResponseWriter
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// http.ResponseController가 원본 ResponseWriter에 접근할 수 있도록 함
+func (rec *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// NDJSON 한 줄의 최대 크기
+const maxNDJSONLineBytes = 1024 * 1024
+
+// 일괄 등록 실패 항목
+type importError struct {
+	Line   int               `json:"line"`
+	Reason string            `json:"reason"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// JSON 배열 일괄 등록 시 최대 항목 수
+const maxBatchCreateItems = 1000
+
+// JSON 배열 일괄 등록 (전체를 하나의 트랜잭션으로 추가, 하나라도 실패하면 모두 취소)
+func CreateBooksBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	include, ok := includeParam(w, r)
+	if !ok {
+		return
+	}
+
+	var batch []Book
+	if !decodeJSONBody(w, r, &batch, "잘못된 요청 형식입니다 (책 배열이어야 함)") {
+		return
+	}
+	if len(batch) == 0 {
+		writeError(w, r, http.StatusUnprocessableEntity, "등록할 책이 없습니다", nil)
+		return
+	}
+	if len(batch) > maxBatchCreateItems {
+		writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("한 번에 최대 %d권까지 등록할 수 있습니다", maxBatchCreateItems), nil)
+		return
+	}
+
+	// 저장 전에 전체 검증 (첫 번째 실패 항목 반환)
+	for i, book := range batch {
+		if fieldErrors := validateBook(book); len(fieldErrors) > 0 {
+			writeError(w, r, http.StatusUnprocessableEntity, "입력값 검증 실패", map[string]interface{}{
+				"index":  i,
+				"fields": fieldErrors,
+			})
+			return
+		}
+	}
+
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	created, err := repo.CreateBatch(ctx, batch)
+	if isRetryableWriteError(err) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusServiceUnavailable, "동시 처리 중인 쓰기 요청이 많습니다. 잠시 후 다시 시도하세요", nil)
+		return
+	}
+	var rowErr *batchRowError
+	if errors.As(err, &rowErr) && !errors.Is(err, context.DeadlineExceeded) {
+		if writeDuplicateError(w, r, rowErr.Err, map[string]interface{}{"index": rowErr.Index}) {
+			return
+		}
+		requestLogger(r.Context()).Error("일괄 등록 DB 에러", "index", rowErr.Index, "error", rowErr.Err)
+		writeError(w, r, http.StatusUnprocessableEntity, "DB 저장 실패로 전체 등록이 취소되었습니다", map[string]interface{}{
+			"index": rowErr.Index,
+		})
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("일괄 등록 DB 에러", "error", err)
+		writeDBError(w, r, err, "책 일괄 등록 실패")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bookListResponse(created, include, nil))
+}
+
+// 일괄 가져오기 진행 상태 (검증을 통과한 항목을 배치 단위 트랜잭션으로 저장)
+type bookImporter struct {
+	r        *http.Request
+	imported int
+	errors   []importError
+	batch    []Book
+	lines    []int
+}
+
+func newBookImporter(r *http.Request) *bookImporter {
+	return &bookImporter{
+		r:      r,
+		errors: []importError{},
+		batch:  make([]Book, 0, appConfig.ImportBatchSize),
+		lines:  make([]int, 0, appConfig.ImportBatchSize),
+	}
+}
+
+// 실패 항목 기록
+func (im *bookImporter) fail(line int, reason string, fields map[string]string) {
+	im.errors = append(im.errors, importError{Line: line, Reason: reason, Fields: fields})
+}
+
+// 검증을 통과한 항목을 배치에 추가하고 배치가 차면 저장 (재시도 가능한 쓰기 에러면 false)
+func (im *bookImporter) add(line int, book Book) bool {
+	im.batch = append(im.batch, book)
+	im.lines = append(im.lines, line)
+	if len(im.batch) >= appConfig.ImportBatchSize {
+		return im.flush()
+	}
+	return true
+}
+
+// 모인 배치를 하나의 트랜잭션으로 저장 (동시 트랜잭션 한도 초과, 잠금 대기 시간 초과 시 false)
+func (im *bookImporter) flush() bool {
+	if len(im.batch) == 0 {
+		return true
+	}
+	ctx, cancel := dbContext(im.r.Context())
+	created, err := repo.CreateBatch(ctx, im.batch)
+	cancel()
+	if isRetryableWriteError(err) {
+		return false
+	}
+	if err != nil {
+		requestLogger(im.r.Context()).Error("일괄 등록 DB 에러", "error", err)
+		// 중복 키로 실패한 행은 이유를 따로 표시 (같은 트랜잭션의 나머지 행도 함께 취소됨)
+		var rowErr *batchRowError
+		var dup *duplicateKeyError
+		isRowErr := errors.As(err, &rowErr)
+		for i, line := range im.lines {
+			switch {
+			case isRowErr && rowErr.Index == i && errors.As(rowErr.Err, &dup):
+				im.fail(line, dup.Error(), nil)
+			case isRowErr && rowErr.Index != i:
+				im.fail(line, "같은 배치의 다른 행 저장 실패로 취소", nil)
+			default:
+				im.fail(line, "DB 저장 실패", nil)
+			}
+		}
+	} else {
+		im.imported += len(created)
+	}
+	im.batch = im.batch[:0]
+	im.lines = im.lines[:0]
+	return true
+}
+
+// 재시도 가능한 쓰기 에러면 지금까지의 결과와 함께 503 응답
+func (im *bookImporter) writeBusy(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	writeError(w, im.r, http.StatusServiceUnavailable, "동시 처리 중인 쓰기 요청이 많습니다. 잠시 후 다시 시도하세요", map[string]interface{}{
+		"imported":    im.imported,
+		"failed":      len(im.errors),
+		"errors":      im.errors,
+		"resume_line": im.lines[0],
+	})
+}
+
+// 결과 요약 응답
+func (im *bookImporter) writeSummary(w http.ResponseWriter) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imported": im.imported,
+		"failed":   len(im.errors),
+		"errors":   im.errors,
+	})
+}
+
+// NDJSON 일괄 등록 (한 줄씩 읽어 배치 단위 트랜잭션으로 추가)
+func ImportBooksNDJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	im := newBookImporter(r)
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var book Book
+		if err := json.Unmarshal(text, &book); err != nil {
+			im.fail(line, "잘못된 JSON 형식입니다", nil)
+			continue
+		}
+		if fieldErrors := validateBook(book); len(fieldErrors) > 0 {
+			im.fail(line, "입력값 검증 실패", fieldErrors)
+			continue
+		}
+		if !im.add(line, book) {
+			im.writeBusy(w)
+			return
+		}
+	}
+	if !im.flush() {
+		im.writeBusy(w)
+		return
+	}
+
+	if err := scanner.Err(); err != nil {
+		requestLogger(r.Context()).Error("NDJSON 읽기 에러", "error", err)
+		im.fail(line+1, "본문 읽기 실패", nil)
+	}
+
+	im.writeSummary(w)
+}
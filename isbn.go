@@ -0,0 +1,54 @@
+package main
+
+import "strings"
+
+// ISBN 정규화 (하이픈/공백 제거, 체크 문자 x는 대문자로)
+func normalizeISBN(isbn string) string {
+	isbn = strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn))
+	return strings.ToUpper(isbn)
+}
+
+// 정규화된 ISBN-10/ISBN-13 형식과 체크섬 확인
+func validISBN(isbn string) bool {
+	switch len(isbn) {
+	case 10:
+		// 가중치 10..1의 합이 11의 배수 (마지막 자리 X는 10)
+		sum := 0
+		for i, c := range isbn {
+			var digit int
+			switch {
+			case c >= '0' && c <= '9':
+				digit = int(c - '0')
+			case c == 'X' && i == 9:
+				digit = 10
+			default:
+				return false
+			}
+			sum += digit * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		// 가중치 1, 3을 번갈아 곱한 합이 10의 배수
+		sum := 0
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return false
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(c-'0') * weight
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+// 빈 ISBN은 NULL로 저장 (고유 인덱스는 NULL이 아닌 값에만 적용)
+func nullableISBN(isbn string) interface{} {
+	if isbn == "" {
+		return nil
+	}
+	return isbn
+}
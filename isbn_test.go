@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValidISBN(t *testing.T) {
+	tests := []struct {
+		isbn string
+		want bool
+	}{
+		{"9780306406157", true},
+		{"9780306406158", false},
+		{"0306406152", true},
+		{"0306406153", false},
+		{"080442957X", true},
+		{"X804429570", false},
+		{"978030640615", false},
+		{"978030640615A", false},
+	}
+	for _, tt := range tests {
+		if got := validISBN(tt.isbn); got != tt.want {
+			t.Errorf("validISBN(%q) = %v, 원하는 값 %v", tt.isbn, got, tt.want)
+		}
+	}
+}
+
+func TestCreateBookDuplicateISBN(t *testing.T) {
+	h := newTestHandler(t, nil)
+	createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969,"isbn":"978-0-306-40615-7"}`)
+
+	// 하이픈 없이 보내도 정규화한 값이 같으면 중복
+	rec := doRequest(t, h, "POST", "/v1/books", `{"title":"토지 2","author":"박경리","year":1970,"isbn":"9780306406157"}`, nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("상태 코드 = %d, 원하는 값 409 (%s)", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Field string `json:"field"`
+	}
+	decodeResponse(t, rec, &body)
+	if body.Field != "isbn" {
+		t.Errorf("field = %q, 원하는 값 isbn", body.Field)
+	}
+
+	rec = doRequest(t, h, "GET", "/v1/books/count", "", nil)
+	var count struct {
+		Count int `json:"count"`
+	}
+	decodeResponse(t, rec, &count)
+	if count.Count != 1 {
+		t.Errorf("책 수 = %d, 원하는 값 1", count.Count)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// 검증된 JWT 클레임 컨텍스트 키
+const jwtClaimsKey contextKey = "jwtClaims"
+
+// HS256 비밀키 최소 길이 (바이트)
+const minJWTSecretLength = 32
+
+// exp/nbf 확인 시 허용하는 시계 오차
+const jwtClockSkew = 30 * time.Second
+
+// JWT 클레임 (검증 후 핸들러에서 jwtClaimsFromContext로 조회)
+type jwtClaims map[string]interface{}
+
+// sub 클레임 (없으면 빈 문자열)
+func (c jwtClaims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// 요청 컨텍스트의 JWT 클레임 (API 키로 인증한 요청이면 false)
+func jwtClaimsFromContext(ctx context.Context) (jwtClaims, bool) {
+	claims, ok := ctx.Value(jwtClaimsKey).(jwtClaims)
+	return claims, ok
+}
+
+// JWT 검증 실패 (Reason은 401 응답의 reason 필드)
+type jwtError struct {
+	Reason  string
+	Message string
+}
+
+func (e *jwtError) Error() string {
+	return e.Message
+}
+
+func jwtMalformed(message string) *jwtError {
+	return &jwtError{Reason: "malformed", Message: message}
+}
+
+// JWT 서명 검증기 (HS256 비밀키, RS256 공개키 중 설정된 것만 허용)
+type jwtVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+}
+
+// JWT 검증기 생성 (둘 다 설정되지 않으면 nil - Bearer 토큰 인증 사용 안 함)
+func newJWTVerifier(secret []byte, publicKey *rsa.PublicKey) *jwtVerifier {
+	if len(secret) == 0 && publicKey == nil {
+		return nil
+	}
+	return &jwtVerifier{secret: secret, publicKey: publicKey}
+}
+
+// 토큰 서명과 exp/nbf를 확인하고 클레임 반환 (실패하면 *jwtError)
+// 알고리즘은 헤더의 alg를 따르되 설정된 키 종류와 맞아야 하며 none은 허용하지 않는다.
+func (v *jwtVerifier) verify(token string, now time.Time) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, jwtMalformed("잘못된 형식의 토큰입니다")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, jwtMalformed("토큰 헤더를 해석할 수 없습니다")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, jwtMalformed("토큰 서명을 해석할 수 없습니다")
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && len(v.secret) > 0:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, &jwtError{Reason: "bad_signature", Message: "토큰 서명이 올바르지 않습니다"}
+		}
+	case header.Alg == "RS256" && v.publicKey != nil:
+		digest := sha256.Sum256(signingInput)
+		if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return nil, &jwtError{Reason: "bad_signature", Message: "토큰 서명이 올바르지 않습니다"}
+		}
+	default:
+		return nil, &jwtError{Reason: "unsupported_alg", Message: fmt.Sprintf("지원하지 않는 서명 알고리즘입니다: %q", header.Alg)}
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, jwtMalformed("토큰 클레임을 해석할 수 없습니다")
+	}
+
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return nil, jwtMalformed("토큰에 exp 클레임이 없습니다")
+	}
+	expSeconds, err := exp.Float64()
+	if err != nil {
+		return nil, jwtMalformed("exp 클레임이 숫자가 아닙니다")
+	}
+	if now.Add(-jwtClockSkew).After(time.Unix(int64(expSeconds), 0)) {
+		return nil, &jwtError{Reason: "expired", Message: "만료된 토큰입니다"}
+	}
+	if raw, ok := claims["nbf"]; ok {
+		nbf, ok := raw.(json.Number)
+		nbfSeconds, err := nbf.Float64()
+		if !ok || err != nil {
+			return nil, jwtMalformed("nbf 클레임이 숫자가 아닙니다")
+		}
+		if now.Add(jwtClockSkew).Before(time.Unix(int64(nbfSeconds), 0)) {
+			return nil, &jwtError{Reason: "not_yet_valid", Message: "아직 사용할 수 없는 토큰입니다"}
+		}
+	}
+
+	return claims, nil
+}
+
+// base64url JSON 토큰 조각 디코딩 (숫자는 json.Number로 유지)
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// JWT_PUBLIC_KEY 파싱 (PEM 문자열 또는 PEM 파일 경로, 비어 있으면 nil)
+func parseJWTPublicKey(value string) (*rsa.PublicKey, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, fmt.Errorf("JWT_PUBLIC_KEY 파일을 읽을 수 없습니다: %w", err)
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("JWT_PUBLIC_KEY가 PEM 형식이 아닙니다")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+		return nil, errors.New("JWT_PUBLIC_KEY 인증서의 키가 RSA 공개키가 아닙니다")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_PUBLIC_KEY를 해석할 수 없습니다: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("JWT_PUBLIC_KEY가 RSA 공개키가 아닙니다")
+	}
+	return key, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// 요청 ID 컨텍스트 키
+const requestIDKey contextKey = "requestID"
+
+// JSON 로거 설정
+// 기본 로거로 등록하므로 기존 log.Printf 출력도 같은 JSON 형식(level=INFO)으로 남는다.
+// 모든 로그 라인에 빌드 정보(version, commit)를 넣어 어느 빌드에서 남긴 로그인지 알 수 있게 한다.
+func initLogger(level slog.Level) {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler).With("version", Version, "commit", Commit, "build_time", BuildTime))
+}
+
+// LOG_LEVEL 값 파싱 (debug, info, warn, error)
+func parseLogLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return level, fmt.Errorf("LOG_LEVEL 값이 올바르지 않습니다 (debug, info, warn, error): %q", value)
+	}
+	return level, nil
+}
+
+// 요청별 로거 (요청 ID가 있으면 모든 로그 라인에 포함)
+func requestLogger(ctx context.Context) *slog.Logger {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
+
+// 요청 ID 생성 (UUID v4)
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// 응답 상태 코드 기록용 ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// http.ResponseController가 원본 ResponseWriter에 접근할 수 있도록 함
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// 요청 로그 미들웨어
+// 요청마다 ID를 발급해 컨텍스트와 X-Request-ID 응답 헤더에 넣고, 처리 후 접근 로그를 남긴다.
+func requestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := newRequestID()
+		w.Header().Set("X-Request-ID", id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		requestLogger(r.Context()).Info("요청 처리",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}
@@ -1,22 +1,45 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+	_ "time/tzdata" // TZ_OUTPUT용 시간대 DB (tzdata가 없는 컨테이너 이미지 대비)
+	"unicode/utf8"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	mssql "github.com/denisenkom/go-mssqldb"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // DB 연결 변수(MSSQL)
 var db *sql.DB
 
+// 책 테이블 전체 이름 (DB_CATALOG.DB_SCHEMA.DB_TABLE, 시작 시 설정)
+var bookTable = "bz.dbo.tbl_book"
+
+// 애플리케이션 설정 (핸들러에서 참조)
+var appConfig *Config
+
 // 설정 구조체
 type Config struct {
 	DBServer   string
@@ -24,169 +47,1316 @@ type Config struct {
 	DBPassword string
 	DBPort     string
 	DBName     string
-	APIKey     string
 	Port       string
+
+	// DB 연결 암호화 (DB_ENCRYPT: disable, false, true - 비어 있으면 드라이버 기본값) 및 서버 인증서 검증 생략 여부
+	DBEncrypt                string
+	DBTrustServerCertificate bool
+
+	// sys.dm_exec_sessions의 program_name으로 보이는 애플리케이션 이름 (비어 있으면 드라이버 기본값)
+	DBAppName string
+
+	// 책 저장소 종류 (mssql, memory - memory는 DB 없이 실행하며 재시작 시 데이터가 사라짐)
+	DBDriver string
+
+	// 책 테이블 위치 (쿼리에 그대로 들어가므로 식별자 형식만 허용)
+	DBCatalog string
+	DBSchema  string
+	DBTable   string
+
+	// 허용 API 키 목록 (API_KEY, 쉼표 구분 - 키 교체 시 새 키와 기존 키를 함께 등록)
+	APIKeys map[string]struct{}
+
+	// NDJSON 일괄 등록 시 트랜잭션당 처리 건수
+	ImportBatchSize int
+
+	// year를 숫자 문자열("1954")로 보내도 허용할지 여부
+	LenientNumbers bool
+
+	// 레거시 라우트 폐기 공지일 / 제거 예정일 (YYYY-MM-DD, 비어 있으면 헤더 미사용)
+	DeprecationDate time.Time
+	SunsetDate      time.Time
+
+	// API 키별 일일 쓰기 요청 할당량 (0이면 제한 없음)
+	WriteQuotaPerDay int
+
+	// API 키별 초당 요청 수 제한 (0이면 제한 없음) 및 순간 허용량 (0이면 RATE_LIMIT_RPS와 같음)
+	RateLimitRPS   int
+	RateLimitBurst int
+	// 라우트 이름별 요청 수 제한 (RATE_LIMIT_ROUTES, 지정한 라우트는 위 한도 대신 라우트별 한도 적용)
+	RateLimitRoutes map[string]routeRateLimit
+
+	// 동시 쓰기 트랜잭션 최대 수 (0이면 제한 없음) 및 슬롯 대기 시간
+	MaxConcurrentTx int
+	TxWaitTimeout   time.Duration
+
+	// 쓰기 트랜잭션의 잠금 대기 한도 (밀리초, 0이면 무제한)
+	LockTimeoutMS int
+
+	// 디버그 모드 (X-Handler 응답 헤더 등)
+	Debug bool
+
+	// CORS 허용 Origin 목록 (쉼표 구분, "*"이면 모든 Origin, 비어 있으면 CORS 헤더 미사용)
+	AllowedOrigins map[string]struct{}
+
+	// 클라이언트가 X-Feature-Flags 헤더로 켤 수 있는 기능 플래그 허용 목록
+	// (problem_json: 그 요청의 에러를 PROBLEM_JSON처럼 problem+json으로 응답)
+	FeatureFlags map[string]struct{}
+
+	// API 키별 테넌트 (비어 있지 않으면 멀티 테넌시 사용)
+	TenantKeys map[string]string
+
+	// 목록 결과가 이 건수를 넘으면 스트리밍 응답 (0이면 사용 안 함)
+	StreamThreshold int
+
+	// 로그 레벨 (debug, info, warn, error)
+	LogLevel slog.Level
+
+	// 요청당 DB 쿼리 제한 시간 (0이면 무제한)
+	DBQueryTimeout time.Duration
+
+	// 초당 새로 열 수 있는 DB 연결 수 (0이면 제한 없음)
+	DBNewConnsPerSec int
+
+	// 시작 시 DB 연결 재시도 횟수 (0이면 재시도 안 함) 및 첫 재시도 대기 시간 (재시도마다 2배)
+	DBConnectRetries int
+	DBConnectBackoff time.Duration
+
+	// DB 연결 풀 설정 (최대 연결 수와 연결 수명은 0이면 무제한)
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+
+	// TLS 인증서/키 파일 (둘 다 설정하면 HTTPS로 서비스, 둘 다 비어 있으면 HTTP)
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// 정규 호스트 (다른 Host로 들어온 요청은 301 리다이렉트, 비어 있으면 사용 안 함)
+	CanonicalHost             string
+	CanonicalHostUseForwarded bool
+
+	// 삭제 성공 시 본문 없이 204 응답 (false면 기존처럼 200 + 메시지)
+	DeleteNoContent bool
+
+	// 삭제 시 행을 지우지 않고 deleted_at만 기록 (조회에서는 제외, ?include_deleted=true로 포함)
+	SoftDelete bool
+
+	// 모든 에러를 RFC 7807 problem+json 형식으로 응답
+	ProblemJSON bool
+
+	// 읽기 시 풀에서 꺼낸 연결을 SELECT 1로 검증 (읽기마다 왕복 1회 추가)
+	ValidateConnOnCheckout bool
+
+	// JSON 요청 본문 최대 크기 (바이트, 초과 시 413)
+	MaxBodyBytes int64
+
+	// 책 추가 Idempotency-Key 보관 시간 (0이면 사용 안 함)
+	IdempotencyTTL time.Duration
+
+	// 책 추가 쓰기 큐 (WRITE_QUEUE_SIZE가 0이면 사용 안 함)
+	WriteQueueSize    int
+	WriteQueueWorkers int
+	WriteBatchMax     int
+	WriteBatchWait    time.Duration
+
+	// 종료 시 진행 중인 요청을 기다리는 최대 시간
+	ShutdownTimeout time.Duration
+
+	// 요청 하나의 전체 처리 제한 시간 (초과 시 503, 0이면 무제한)
+	RequestTimeout time.Duration
+
+	// CSV 내보내기 전체 제한 시간 (REQUEST_TIMEOUT, DB_QUERY_TIMEOUT 대신 적용, 0이면 무제한)
+	ExportTimeout time.Duration
+
+	// 모든 경로 앞에 붙는 기본 경로 (예: /catalog, 비어 있으면 접두사 없음)
+	BasePath string
+
+	// 응답 시각(regdate, updated_at)의 시간대 (TZ_OUTPUT, IANA 이름, 기본 UTC)
+	TZOutput *time.Location
+
+	// Bearer JWT 검증 키 (HS256 비밀키 / RS256 공개키, 둘 다 비어 있으면 API 키만 사용)
+	JWTSecret    []byte
+	JWTPublicKey *rsa.PublicKey
+
+	// JWT로 쓰기 라우트를 호출할 때 필요한 스코프 (비어 있으면 인증만 요구)
+	JWTWriteScope string
+	// 라우트 이름별 필요 스코프 (JWT_ROUTE_SCOPES, 조회/쓰기 기본값보다 우선)
+	JWTRouteScopes map[string]string
 }
 
-// 환경변수 로드 함수
-func loadConfig() *Config {
-	// .env 파일이 존재하면 로드 (개발환경용)
-	if _, err := os.Stat(".env"); err == nil {
-		err := godotenv.Load()
-		if err != nil {
-			log.Println("Warning: .env 파일 로드 실패, 시스템 환경변수 사용")
-		}
+// 환경변수 로드 함수
+func loadConfig() *Config {
+	// .env 파일이 존재하면 로드 (개발환경용)
+	if _, err := os.Stat(".env"); err == nil {
+		err := godotenv.Load()
+		if err != nil {
+			log.Println("Warning: .env 파일 로드 실패, 시스템 환경변수 사용")
+		}
+	}
+
+	config := &Config{
+		DBServer:   getEnv("DB_SERVER", ""),
+		DBUser:     getEnv("DB_USER", ""),
+		DBPassword: getEnv("DB_PASSWORD", ""),
+		DBPort:     getEnv("DB_PORT", "1433"),
+		DBName:     getEnv("DB_NAME", ""),
+		Port:       getEnv("PORT", "8000"),
+
+		DBEncrypt:                strings.ToLower(getEnv("DB_ENCRYPT", "")),
+		DBTrustServerCertificate: getEnvBool("DB_TRUST_SERVER_CERTIFICATE", false),
+		DBAppName:                getEnv("DB_APP_NAME", ""),
+
+		DBDriver:  strings.ToLower(getEnv("DB_DRIVER", "mssql")),
+		DBCatalog: getEnv("DB_CATALOG", "bz"),
+		DBSchema:  getEnv("DB_SCHEMA", "dbo"),
+		DBTable:   getEnv("DB_TABLE", "tbl_book"),
+
+		APIKeys: parseAPIKeys(getEnv("API_KEY", "")),
+
+		ImportBatchSize: getEnvInt("IMPORT_BATCH_SIZE", 100),
+		LenientNumbers:  getEnvBool("LENIENT_NUMBERS", false),
+		DeprecationDate: getEnvDate("DEPRECATION_DATE"),
+		SunsetDate:      getEnvDate("SUNSET_DATE"),
+
+		WriteQuotaPerDay: getEnvInt("WRITE_QUOTA_PER_DAY", 0),
+
+		RateLimitRPS:   getEnvInt("RATE_LIMIT_RPS", 0),
+		RateLimitBurst: getEnvInt("RATE_LIMIT_BURST", 0),
+
+		MaxConcurrentTx: getEnvInt("MAX_CONCURRENT_TX", 0),
+		TxWaitTimeout:   getEnvDuration("TX_WAIT_TIMEOUT", 2*time.Second),
+		LockTimeoutMS:   getEnvInt("LOCK_TIMEOUT_MS", 0),
+
+		Debug:        getEnvBool("DEBUG", false),
+		FeatureFlags: parseStringSet(getEnv("FEATURE_FLAGS", "")),
+
+		AllowedOrigins: parseStringSet(getEnv("ALLOWED_ORIGINS", "")),
+
+		StreamThreshold:  getEnvInt("STREAM_THRESHOLD", 0),
+		DBQueryTimeout:   getEnvDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+		DBNewConnsPerSec: getEnvInt("DB_NEW_CONNS_PER_SEC", 0),
+
+		DBConnectRetries: getEnvInt("DB_CONNECT_RETRIES", 5),
+		DBConnectBackoff: getEnvDuration("DB_CONNECT_BACKOFF", time.Second),
+
+		DBMaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+
+		TLSCertFile: getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:  getEnv("TLS_KEY_FILE", ""),
+
+		CanonicalHost:             getEnv("CANONICAL_HOST", ""),
+		CanonicalHostUseForwarded: getEnvBool("CANONICAL_HOST_USE_FORWARDED", false),
+
+		DeleteNoContent: getEnvBool("DELETE_NO_CONTENT", false),
+		SoftDelete:      getEnvBool("SOFT_DELETE", false),
+		ProblemJSON:     getEnvBool("PROBLEM_JSON", false),
+
+		ValidateConnOnCheckout: getEnvBool("VALIDATE_CONN_ON_CHECKOUT", false),
+
+		MaxBodyBytes:   int64(getEnvInt("MAX_BODY_BYTES", 1<<20)),
+		IdempotencyTTL: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		WriteQueueSize:    getEnvInt("WRITE_QUEUE_SIZE", 0),
+		WriteQueueWorkers: getEnvInt("WRITE_QUEUE_WORKERS", 2),
+		WriteBatchMax:     getEnvInt("WRITE_BATCH_MAX", 50),
+		WriteBatchWait:    getEnvDuration("WRITE_BATCH_WAIT", 20*time.Millisecond),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+		ExportTimeout:   getEnvDuration("EXPORT_TIMEOUT", 10*time.Minute),
+
+		BasePath: strings.TrimRight(getEnv("BASE_PATH", ""), "/"),
+	}
+
+	logLevel, err := parseLogLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.LogLevel = logLevel
+
+	tzOutput, err := time.LoadLocation(getEnv("TZ_OUTPUT", "UTC"))
+	if err != nil {
+		log.Fatalf("TZ_OUTPUT 값이 올바른 IANA 시간대 이름이 아닙니다 (예: Asia/Seoul): %v", err)
+	}
+	config.TZOutput = tzOutput
+
+	config.JWTSecret = []byte(getEnv("JWT_SECRET", ""))
+	if len(config.JWTSecret) > 0 && len(config.JWTSecret) < minJWTSecretLength {
+		log.Fatalf("JWT_SECRET은 %d바이트 이상이어야 합니다.", minJWTSecretLength)
+	}
+	jwtPublicKey, err := parseJWTPublicKey(getEnv("JWT_PUBLIC_KEY", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.JWTPublicKey = jwtPublicKey
+
+	config.JWTWriteScope = strings.TrimSpace(getEnv("JWT_WRITE_SCOPE", "books:write"))
+	routeScopes, err := parseRouteScopes(getEnv("JWT_ROUTE_SCOPES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.JWTRouteScopes = routeScopes
+
+	rateLimitRoutes, err := parseRouteRateLimits(getEnv("RATE_LIMIT_ROUTES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.RateLimitRoutes = rateLimitRoutes
+
+	tenantKeys, err := parseTenantKeys(getEnv("API_KEY_TENANTS", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.TenantKeys = tenantKeys
+	if len(config.TenantKeys) > 0 {
+		for key := range config.APIKeys {
+			if _, ok := config.TenantKeys[key]; !ok {
+				log.Fatal("멀티 테넌시 사용 시 API_KEY의 모든 키도 API_KEY_TENANTS에 테넌트가 지정되어야 합니다.")
+			}
+		}
+	}
+
+	// 필수 환경변수 누락은 시작 점검(runStartupChecks)에서 확인
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		log.Fatal("TLS_CERT_FILE과 TLS_KEY_FILE은 함께 설정해야 합니다.")
+	}
+	switch config.DBDriver {
+	case "mssql":
+	case "memory":
+		// 할당량 카운터와 쓰기 큐는 DB에 직접 쓰므로 메모리 저장소와 함께 쓸 수 없음
+		if config.WriteQuotaPerDay > 0 || config.WriteQueueSize > 0 {
+			log.Fatal("DB_DRIVER=memory에서는 WRITE_QUOTA_PER_DAY, WRITE_QUEUE_SIZE를 사용할 수 없습니다.")
+		}
+	default:
+		log.Fatalf("DB_DRIVER는 mssql 또는 memory여야 합니다: %q", config.DBDriver)
+	}
+	for name, value := range map[string]string{
+		"DB_CATALOG": config.DBCatalog,
+		"DB_SCHEMA":  config.DBSchema,
+		"DB_TABLE":   config.DBTable,
+	} {
+		if !sqlIdentifierPattern.MatchString(value) {
+			log.Fatalf("%s 값이 올바른 식별자가 아닙니다 (영문자, 숫자, _만 사용): %q", name, value)
+		}
+	}
+	switch config.DBEncrypt {
+	case "", "disable", "false", "true":
+	default:
+		log.Fatalf("DB_ENCRYPT는 disable, false, true 중 하나여야 합니다: %q", config.DBEncrypt)
+	}
+	if strings.ContainsAny(config.DBAppName, ";=") {
+		log.Fatal("DB_APP_NAME에는 ;와 =를 사용할 수 없습니다.")
+	}
+	if config.ImportBatchSize < 1 {
+		log.Fatal("IMPORT_BATCH_SIZE는 1 이상이어야 합니다.")
+	}
+	if config.WriteQuotaPerDay < 0 {
+		log.Fatal("WRITE_QUOTA_PER_DAY는 0 이상이어야 합니다.")
+	}
+	if config.RateLimitRPS < 0 {
+		log.Fatal("RATE_LIMIT_RPS는 0 이상이어야 합니다.")
+	}
+	if config.RateLimitBurst < 0 {
+		log.Fatal("RATE_LIMIT_BURST는 0 이상이어야 합니다.")
+	}
+	if config.RateLimitBurst == 0 {
+		config.RateLimitBurst = config.RateLimitRPS
+	}
+	if config.MaxConcurrentTx < 0 {
+		log.Fatal("MAX_CONCURRENT_TX는 0 이상이어야 합니다.")
+	}
+	if config.LockTimeoutMS < 0 {
+		log.Fatal("LOCK_TIMEOUT_MS는 0 이상이어야 합니다.")
+	}
+	if config.StreamThreshold < 0 {
+		log.Fatal("STREAM_THRESHOLD는 0 이상이어야 합니다.")
+	}
+	if config.DBQueryTimeout < 0 {
+		log.Fatal("DB_QUERY_TIMEOUT은 0 이상이어야 합니다.")
+	}
+	if config.DBNewConnsPerSec < 0 {
+		log.Fatal("DB_NEW_CONNS_PER_SEC는 0 이상이어야 합니다.")
+	}
+	if config.DBConnectRetries < 0 {
+		log.Fatal("DB_CONNECT_RETRIES는 0 이상이어야 합니다.")
+	}
+	if config.DBConnectBackoff <= 0 {
+		log.Fatal("DB_CONNECT_BACKOFF는 0보다 커야 합니다.")
+	}
+	if config.DBMaxOpenConns < 0 {
+		log.Fatal("DB_MAX_OPEN_CONNS는 0 이상이어야 합니다.")
+	}
+	if config.DBMaxIdleConns < 0 {
+		log.Fatal("DB_MAX_IDLE_CONNS는 0 이상이어야 합니다.")
+	}
+	if config.DBConnMaxLifetime < 0 {
+		log.Fatal("DB_CONN_MAX_LIFETIME은 0 이상이어야 합니다.")
+	}
+	if config.MaxBodyBytes < 1 {
+		log.Fatal("MAX_BODY_BYTES는 1 이상이어야 합니다.")
+	}
+	if config.IdempotencyTTL < 0 {
+		log.Fatal("IDEMPOTENCY_TTL은 0 이상이어야 합니다.")
+	}
+	if config.WriteQueueSize < 0 {
+		log.Fatal("WRITE_QUEUE_SIZE는 0 이상이어야 합니다.")
+	}
+	if config.WriteQueueSize > 0 {
+		if config.WriteQueueWorkers < 1 {
+			log.Fatal("WRITE_QUEUE_WORKERS는 1 이상이어야 합니다.")
+		}
+		if config.WriteBatchMax < 1 || config.WriteBatchMax > maxWriteBatchRows {
+			log.Fatalf("WRITE_BATCH_MAX는 1 이상 %d 이하여야 합니다.", maxWriteBatchRows)
+		}
+	}
+	if config.ShutdownTimeout <= 0 {
+		log.Fatal("SHUTDOWN_TIMEOUT은 0보다 커야 합니다.")
+	}
+	if config.RequestTimeout < 0 {
+		log.Fatal("REQUEST_TIMEOUT은 0 이상이어야 합니다.")
+	}
+	if config.ExportTimeout < 0 {
+		log.Fatal("EXPORT_TIMEOUT은 0 이상이어야 합니다.")
+	}
+	if config.BasePath != "" && !strings.HasPrefix(config.BasePath, "/") {
+		log.Fatal("BASE_PATH는 /로 시작해야 합니다.")
+	}
+
+	return config
+}
+
+// SQL 식별자 형식 (테이블 이름 등 파라미터로 바인딩할 수 없는 값 검증용)
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 환경변수 값 가져오기 (기본값 포함)
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// 정수 환경변수 값 가져오기 (기본값 포함, 형식 오류 시 종료)
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("환경변수 %s의 값이 정수가 아닙니다: %q", key, value)
+	}
+	return n
+}
+
+// 기간 환경변수 값 가져오기 (예: 500ms, 2s, 기본값 포함, 형식 오류 시 종료)
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Fatalf("환경변수 %s의 값이 올바른 기간 형식이 아닙니다: %q", key, value)
+	}
+	return d
+}
+
+// 날짜 환경변수 값 가져오기 (YYYY-MM-DD, 미설정 시 zero time, 형식 오류 시 종료)
+func getEnvDate(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		log.Fatalf("환경변수 %s의 값이 YYYY-MM-DD 형식이 아닙니다: %q", key, value)
+	}
+	return t
+}
+
+// 불리언 환경변수 값 가져오기 (기본값 포함, 형식 오류 시 종료)
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("환경변수 %s의 값이 true/false가 아닙니다: %q", key, value)
+	}
+	return b
+}
+
+// API_KEY 파싱 (쉼표 구분, 빈 항목 무시)
+func parseAPIKeys(value string) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// API 키 비교 (상수 시간 비교, 일치하는 키를 찾아도 끝까지 비교하여 응답 시간으로 키를 추측할 수 없게 함)
+func matchAPIKey(requestAPIKey string, keys map[string]struct{}) bool {
+	matched := 0
+	for key := range keys {
+		matched |= subtle.ConstantTimeCompare([]byte(requestAPIKey), []byte(key))
+	}
+	return matched == 1
+}
+
+// 키에 해당하는 테넌트 조회 (상수 시간 비교)
+func matchTenantKey(requestAPIKey string, tenants map[string]string) (string, bool) {
+	var tenant string
+	matched := 0
+	for key, t := range tenants {
+		if subtle.ConstantTimeCompare([]byte(requestAPIKey), []byte(key)) == 1 {
+			tenant = t
+			matched = 1
+		}
+	}
+	return tenant, matched == 1
+}
+
+// 인증된 클라이언트 식별자 컨텍스트 키
+const clientIDKey contextKey = "clientID"
+
+// 요청 클라이언트 식별자 (API 키 또는 "jwt:"+sub - 속도 제한, 할당량, Idempotency-Key를 클라이언트별로 구분)
+func clientID(r *http.Request) string {
+	if id, ok := r.Context().Value(clientIDKey).(string); ok {
+		return id
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// 인증 미들웨어 (Bearer JWT 또는 API 키)
+//
+// Authorization: Bearer 헤더가 있으면 JWT로 인증하고 검증된 클레임을 컨텍스트에 저장한다.
+// 실패하면 401과 함께 reason(malformed, bad_signature, expired 등)을 알려준다.
+// Bearer 헤더가 없으면 X-API-Key로 인증한다.
+// 멀티 테넌시 사용 시 API 키는 키에 해당하는 테넌트, JWT는 tenant 클레임을 컨텍스트에 저장한다.
+func authMiddleware(apiKeys map[string]struct{}, tenants map[string]string, jwtAuth *jwtVerifier) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if token, ok := bearerToken(r); ok {
+				if jwtAuth == nil {
+					writeError(w, r, http.StatusUnauthorized, "Bearer 토큰 인증을 사용하지 않습니다 (X-API-Key 사용)", nil)
+					return
+				}
+				claims, err := jwtAuth.verify(token, time.Now())
+				if err != nil {
+					reason := "malformed"
+					var jwtErr *jwtError
+					if errors.As(err, &jwtErr) {
+						reason = jwtErr.Reason
+					}
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					writeError(w, r, http.StatusUnauthorized, err.Error(), map[string]interface{}{"reason": reason})
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), jwtClaimsKey, claims)
+				ctx = context.WithValue(ctx, clientIDKey, "jwt:"+claims.Subject())
+				if multiTenant() {
+					tenant, _ := claims["tenant"].(string)
+					if tenant == "" {
+						writeError(w, r, http.StatusUnauthorized, "토큰에 tenant 클레임이 없습니다", map[string]interface{}{"reason": "missing_tenant"})
+						return
+					}
+					ctx = context.WithValue(ctx, tenantKey, tenant)
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			requestAPIKey := r.Header.Get("X-API-Key")
+			if requestAPIKey == "" {
+				writeError(w, r, http.StatusUnauthorized, "API 키가 필요합니다", nil)
+				return
+			}
+
+			// API_KEY에서 뺀 키는 API_KEY_TENANTS에 남아 있어도 거부 (테넌트는 인증 후에만 조회)
+			if !matchAPIKey(requestAPIKey, apiKeys) {
+				writeError(w, r, http.StatusUnauthorized, "유효하지 않은 API 키입니다", nil)
+				return
+			}
+			tenant, tenantKnown := matchTenantKey(requestAPIKey, tenants)
+
+			ctx := context.WithValue(r.Context(), clientIDKey, requestAPIKey)
+			if tenantKnown {
+				ctx = context.WithValue(ctx, tenantKey, tenant)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Authorization: Bearer 토큰 (헤더가 없거나 Bearer가 아니면 false)
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// DB 연결 함수 (에러 메시지에는 비밀번호가 포함되지 않음)
+func connectDB(config *Config) error {
+	// MSSQL 연결 문자열
+	connString := fmt.Sprintf("server=%s;user id=%s;password=%s;port=%s;database=%s",
+		config.DBServer, config.DBUser, config.DBPassword, config.DBPort, config.DBName)
+	if config.DBEncrypt != "" {
+		connString += ";encrypt=" + config.DBEncrypt
+	}
+	if config.DBTrustServerCertificate {
+		connString += ";TrustServerCertificate=true"
+	}
+	if config.DBAppName != "" {
+		connString += ";app name=" + config.DBAppName
+	}
+
+	log.Printf("DB 연결 시도: %s", redactConnString(connString))
+
+	// DB 연결 (DB_NEW_CONNS_PER_SEC 설정 시 새 연결 생성 속도 제한)
+	var err error
+	if config.DBNewConnsPerSec > 0 {
+		var connector *mssql.Connector
+		connector, err = newMSSQLConnector(connString)
+		if err == nil {
+			db = sql.OpenDB(newThrottledConnector(connector, config.DBNewConnsPerSec))
+			log.Printf("새 DB 연결 생성 속도 제한: 초당 %d개", config.DBNewConnsPerSec)
+		}
+	} else {
+		db, err = sql.Open("mssql", connString)
+	}
+	if err != nil {
+		return fmt.Errorf("DB 연결 실패: %s", redactSecret(err.Error(), config.DBPassword))
+	}
+
+	// 연결 풀 설정
+	db.SetMaxOpenConns(config.DBMaxOpenConns)
+	db.SetMaxIdleConns(config.DBMaxIdleConns)
+	db.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	log.Printf("DB 연결 풀: 최대 연결 %d, 유휴 연결 %d, 연결 수명 %s (0은 무제한)",
+		config.DBMaxOpenConns, config.DBMaxIdleConns, config.DBConnMaxLifetime)
+
+	// 연결 테스트 (DB가 아직 뜨지 않았을 수 있으므로 지수 백오프로 재시도)
+	backoff := config.DBConnectBackoff
+	for attempt := 0; ; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+		if attempt >= config.DBConnectRetries {
+			db.Close()
+			return fmt.Errorf("DB 연결 테스트 실패 (%d회 시도): %s", attempt+1, redactSecret(err.Error(), config.DBPassword))
+		}
+		log.Printf("DB 연결 테스트 실패, %s 후 재시도 (%d/%d): %s",
+			backoff, attempt+1, config.DBConnectRetries, redactSecret(err.Error(), config.DBPassword))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	log.Println("MSSQL DB 연결 성공!")
+	return nil
+}
+
+// 로그 출력용 연결 문자열 (비밀번호 마스킹)
+func redactConnString(connString string) string {
+	parts := strings.Split(connString, ";")
+	for i, part := range parts {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "password") {
+			parts[i] = kv[0] + "=****"
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// 에러 메시지 등에 포함된 비밀값 마스킹
+func redactSecret(msg, secret string) string {
+	if secret == "" {
+		return msg
+	}
+	return strings.ReplaceAll(msg, secret, "****")
+}
+
+// 책 구조체 (응답 형태가 항상 같도록 모든 필드를 빈 값이어도 출력)
+type Book struct {
+	ID        string `json:"id" xml:"id"`
+	Title     string `json:"title" xml:"title"`
+	Author    string `json:"author" xml:"author"`
+	Year      int    `json:"year" xml:"year"`
+	ISBN      string `json:"isbn" xml:"isbn"`             // 선택 항목, 하이픈/공백을 뺀 숫자(+X)로 저장
+	Regdate   string `json:"regdate" xml:"regdate"`       // RFC3339 (TZ_OUTPUT 시간대, 기본 UTC)
+	UpdatedAt string `json:"updated_at" xml:"updated_at"` // 마지막 수정 시각 (추가 시 regdate와 같음)
+	Version   int    `json:"version" xml:"version"`       // 수정할 때마다 1씩 증가 (낙관적 동시성 제어)
+
+	// 요청에 year가 있었는지 (생략과 0을 구분해 검증 메시지를 다르게 하기 위함, 응답에는 나가지 않음)
+	yearSet bool
+}
+
+// 조회 컬럼 목록
+const bookColumns = "id, title, author, year, isbn, regdate, updated_at, version"
+
+// INSERT/UPDATE OUTPUT 컬럼 목록 (bookColumns와 같은 순서)
+const insertedBookColumns = "INSERTED.id, INSERTED.title, INSERTED.author, INSERTED.year, INSERTED.isbn, INSERTED.regdate, INSERTED.updated_at, INSERTED.version"
+
+// UPDATE/DELETE의 OUTPUT 절에 쓰는 변경 전 컬럼 목록 (bookColumns와 같은 순서)
+const deletedBookColumns = "DELETED.id, DELETED.title, DELETED.author, DELETED.year, DELETED.isbn, DELETED.regdate, DELETED.updated_at, DELETED.version"
+
+// Scan 가능한 행 (sql.Row, sql.Rows)
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 책 행 스캔 (regdate, updated_at은 RFC3339 문자열로 변환, isbn이 NULL이면 빈 문자열)
+func scanBook(row rowScanner) (Book, error) {
+	return scanBookColumns(row, selectableFields)
+}
+
+// OUTPUT DELETED, INSERTED 컬럼을 이어 붙인 행을 수정 전/후 책으로 스캔
+func scanBookChange(row rowScanner) (Book, Book, error) {
+	var oldBook Book
+	newBook, err := scanBook(scanFunc(func(newDest ...interface{}) error {
+		var err error
+		oldBook, err = scanBook(scanFunc(func(oldDest ...interface{}) error {
+			return row.Scan(append(oldDest, newDest...)...)
+		}))
+		return err
+	}))
+	return oldBook, newBook, err
+}
+
+// 함수를 rowScanner로 사용 (여러 책을 한 행에서 읽을 때 대상 포인터를 모으는 용도)
+type scanFunc func(dest ...interface{}) error
+
+func (f scanFunc) Scan(dest ...interface{}) error {
+	return f(dest...)
+}
+
+// 지정한 컬럼만 SELECT한 행 스캔 (columns는 SELECT 순서, 조회하지 않은 필드는 빈 값)
+func scanBookColumns(row rowScanner, columns []string) (Book, error) {
+	var book Book
+	var isbn sql.NullString
+	var regdate, updatedAt sql.NullTime
+	dest := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			dest[i] = &book.ID
+		case "title":
+			dest[i] = &book.Title
+		case "author":
+			dest[i] = &book.Author
+		case "year":
+			dest[i] = &book.Year
+		case "isbn":
+			dest[i] = &isbn
+		case "regdate":
+			dest[i] = &regdate
+		case "updated_at":
+			dest[i] = &updatedAt
+		case "version":
+			dest[i] = &book.Version
+		default:
+			return Book{}, fmt.Errorf("알 수 없는 컬럼입니다: %s", column)
+		}
+	}
+	if err := row.Scan(dest...); err != nil {
+		return Book{}, err
+	}
+	book.ISBN = isbn.String
+	if regdate.Valid {
+		book.Regdate = formatTimestamp(regdate.Time)
+	}
+	if updatedAt.Valid {
+		book.UpdatedAt = formatTimestamp(updatedAt.Time)
+	}
+	return book, nil
+}
+
+// 응답용 시각 문자열 (TZ_OUTPUT 시간대의 RFC3339, 설정 전이면 UTC)
+// DB에는 GETUTCDATE()로 UTC 시각을 저장하므로 드라이버가 돌려준 값을 UTC로 보고 변환한다.
+func formatTimestamp(t time.Time) string {
+	loc := time.UTC
+	if appConfig != nil && appConfig.TZOutput != nil {
+		loc = appConfig.TZOutput
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// 책 JSON 디코딩 (LENIENT_NUMBERS=true이면 year에 숫자 문자열도 허용)
+// 오타 난 필드가 조용히 무시되지 않도록 알 수 없는 필드는 에러로 처리한다.
+func (b *Book) UnmarshalJSON(data []byte) error {
+	type bookAlias Book
+	aux := struct {
+		*bookAlias
+		Year json.RawMessage `json:"year"`
+	}{bookAlias: (*bookAlias)(b)}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&aux); err != nil {
+		return err
+	}
+	b.ISBN = normalizeISBN(b.ISBN)
+	if len(aux.Year) == 0 || string(aux.Year) == "null" {
+		return nil
+	}
+
+	year, err := parseYear(aux.Year, appConfig != nil && appConfig.LenientNumbers)
+	if err != nil {
+		return &fieldTypeError{Field: "year", Message: "정수여야 합니다"}
+	}
+	b.Year, b.yearSet = year, true
+	return nil
+}
+
+// year 값 파싱 (lenient이면 "1954" 같은 숫자 문자열을 정수로 변환)
+func parseYear(raw json.RawMessage, lenient bool) (int, error) {
+	var year int
+	err := json.Unmarshal(raw, &year)
+	if err == nil || !lenient {
+		return year, err
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return 0, err
+	}
+	year, convErr := strconv.Atoi(strings.TrimSpace(s))
+	if convErr != nil {
+		return 0, fmt.Errorf("year 값이 숫자가 아닙니다: %q", s)
+	}
+	return year, nil
+}
+
+// 필드 정의 구조체 (검증 규칙 + DB 컬럼 정보)
+type FieldDef struct {
+	Name      string `json:"name" xml:"name"`
+	Type      string `json:"type" xml:"type"`
+	Required  bool   `json:"required" xml:"required"`
+	ReadOnly  bool   `json:"readonly" xml:"readonly"`
+	MaxLength int    `json:"max_length,omitempty" xml:"max_length,omitempty"`
+	Min       int    `json:"min,omitempty" xml:"min,omitempty"`
+	Max       int    `json:"max,omitempty" xml:"max,omitempty"`
+	DBType    string `json:"db_type,omitempty" xml:"db_type,omitempty"`
+	DBLength  int64  `json:"db_length,omitempty" xml:"db_length,omitempty"`
+	Nullable  bool   `json:"nullable" xml:"nullable"`
+}
+
+// 시작 시 조회한 테이블 컬럼 정보 (컬럼명 소문자 기준)
+var columnTypes = map[string]*sql.ColumnType{}
+
+// 책 필드 검증 규칙
+func bookFieldDefs() []FieldDef {
+	return []FieldDef{
+		{Name: "id", Type: "string", ReadOnly: true},
+		{Name: "title", Type: "string", Required: true, MaxLength: 255},
+		{Name: "author", Type: "string", Required: true, MaxLength: 255},
+		{Name: "year", Type: "integer", Required: true, Min: 1000, Max: time.Now().Year() + 1},
+		{Name: "isbn", Type: "string", MaxLength: 13},
+		{Name: "regdate", Type: "datetime", ReadOnly: true},
+		{Name: "updated_at", Type: "datetime", ReadOnly: true},
+		{Name: "version", Type: "integer", ReadOnly: true},
+	}
+}
+
+// 헬스체크 엔드포인트 추가
+//
+// 응답 형식 {"status":"healthy","time":...}은 로드밸런서/배포 스크립트가 그대로 확인하므로
+// 다른 API 응답에 적용하는 공통 응답 형식(envelope) 대상에서 제외한다.
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// 준비 상태 확인 시 DB ping 제한 시간
+const readyPingTimeout = 2 * time.Second
+
+// 준비 상태 확인 엔드포인트 (DB 연결 가능 여부)
+//
+// /health는 프로세스 생존 여부만 확인하고, 트래픽을 받을 수 있는지는 /ready로 확인한다.
+// /health와 마찬가지로 공통 응답 형식 대상에서 제외한다.
+// 종료 시그널을 받은 뒤에는 로드밸런서가 인스턴스를 빼도록 DB 상태와 관계없이 503으로 응답한다.
+func ReadyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "draining"})
+		return
+	}
+
+	// 메모리 저장소는 항상 준비 상태
+	if db == nil {
+		json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyPingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "not ready",
+			"error":  redactSecret(err.Error(), appConfig.DBPassword),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+}
+
+// 책 필드 검증 (첫 오류에서 멈추지 않고 모든 필드 오류를 모아서 반환)
+func validateBook(book Book) map[string]string {
+	rules := map[string]FieldDef{}
+	for _, f := range bookFieldDefs() {
+		rules[f.Name] = f
+	}
+
+	fieldErrors := map[string]string{}
+	validateString := func(name, value string) {
+		rule := rules[name]
+		if rule.Required && strings.TrimSpace(value) == "" {
+			fieldErrors[name] = "필수 항목입니다"
+			return
+		}
+		if rule.MaxLength > 0 && utf8.RuneCountInString(value) > rule.MaxLength {
+			fieldErrors[name] = fmt.Sprintf("최대 %d자까지 입력할 수 있습니다", rule.MaxLength)
+		}
+	}
+	validateString("title", book.Title)
+	validateString("author", book.Author)
+
+	// year를 보내지 않은 것(누락)과 0을 보낸 것(범위 오류)을 구분해 안내
+	yearRule := rules["year"]
+	if yearRule.Required && !book.yearSet && book.Year == 0 {
+		fieldErrors["year"] = "필수 항목입니다"
+	} else if book.Year < yearRule.Min || book.Year > yearRule.Max {
+		fieldErrors["year"] = fmt.Sprintf("%d 이상 %d 이하여야 합니다", yearRule.Min, yearRule.Max)
+	}
+
+	if book.ISBN != "" && !validISBN(book.ISBN) {
+		fieldErrors["isbn"] = "올바른 ISBN-10 또는 ISBN-13이 아닙니다"
+	}
+
+	return fieldErrors
+}
+
+// 검증 실패 응답 (422)
+func writeValidationError(w http.ResponseWriter, r *http.Request, fieldErrors map[string]string) {
+	writeError(w, r, http.StatusUnprocessableEntity, "입력값 검증 실패", map[string]interface{}{"fields": fieldErrors})
+}
+
+// 버전 충돌 응답 (409, 현재 버전 포함)
+func writeVersionConflict(w http.ResponseWriter, r *http.Request, conflict *versionConflictError) {
+	writeError(w, r, http.StatusConflict, "다른 요청이 먼저 수정했습니다. 다시 조회한 뒤 수정하세요", map[string]interface{}{"current_version": conflict.Current})
+}
+
+// 책 필드 스키마 조회 (검증 규칙 + 컬럼 정보)
+func GetBookSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	fields := bookFieldDefs()
+	for i := range fields {
+		ct, ok := columnTypes[fields[i].Name]
+		if !ok {
+			continue
+		}
+		fields[i].DBType = ct.DatabaseTypeName()
+		if length, ok := ct.Length(); ok {
+			fields[i].DBLength = length
+		}
+		if nullable, ok := ct.Nullable(); ok {
+			fields[i].Nullable = nullable
+		}
+	}
+
+	writeFormatted(w, format, struct {
+		Fields []FieldDef `json:"fields" xml:"field"`
+	}{fields}, "schema", "")
+}
+
+// 책 목록 응답 (결과가 없어도 null이 아닌 빈 배열로 응답)
+func writeBookList(w http.ResponseWriter, format string, list []Book, include map[string]struct{}, fields []string) {
+	if list == nil {
+		list = []Book{}
+	}
+	writeFormatted(w, format, bookListResponse(list, include, fields), "books", "book")
+}
+
+// 키셋 페이지 목록 응답 (nextCursor가 비어 있으면 next_cursor는 null)
+func writeBookCursorPage(w http.ResponseWriter, format string, list []Book, include map[string]struct{}, fields []string, nextCursor string) {
+	if list == nil {
+		list = []Book{}
+	}
+	page := bookCursorPage{Books: bookListResponse(list, include, fields)}
+	if nextCursor != "" {
+		page.NextCursor = &nextCursor
+	}
+	writeFormatted(w, format, page, "books", "")
+}
+
+// 모든 책 정보 조회
+func GetBooks(w http.ResponseWriter, r *http.Request) {
+	serveBookList(w, r, nil)
+}
+
+// 특정 저자의 책 목록 조회 (저자 이름 전체 일치, 대소문자 무시)
+// /books와 같은 필터/정렬/페이지 파라미터를 지원하고 책이 없으면 404가 아닌 빈 배열로 응답한다.
+func GetAuthorBooks(w http.ResponseWriter, r *http.Request) {
+	author := mux.Vars(r)["author"]
+	serveBookList(w, r, func(filter *bookFilter) {
+		filter.AuthorExact = author
+	})
+}
+
+// 책 목록 응답 (scope가 있으면 쿼리 파라미터로 만든 필터에 경로 조건을 덧붙임)
+func serveBookList(w http.ResponseWriter, r *http.Request, scope func(*bookFilter)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	include, ok := includeParam(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseBookFilter(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter.Tenant = tenantFromContext(r.Context())
+	if scope != nil {
+		scope(&filter)
 	}
 
-	config := &Config{
-		DBServer:   getEnv("DB_SERVER", ""),
-		DBUser:     getEnv("DB_USER", ""),
-		DBPassword: getEnv("DB_PASSWORD", ""),
-		DBPort:     getEnv("DB_PORT", "1433"),
-		DBName:     getEnv("DB_NAME", ""),
-		APIKey:     getEnv("API_KEY", ""),
-		Port:       getEnv("PORT", "8000"),
+	// ?fields=로 조회할 필드 제한 (age/decade 계산에 필요한 year는 응답에 없어도 조회)
+	fields, err := parseFieldsParam(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter.Fields = fields
+	if len(fields) > 0 && len(include) > 0 && !slices.Contains(fields, "year") {
+		filter.Fields = append(append([]string(nil), fields...), "year")
 	}
 
-	// 필수 환경변수 검증
-	if config.DBServer == "" || config.DBUser == "" || config.DBPassword == "" ||
-		config.DBName == "" || config.APIKey == "" {
-		log.Fatal("필수 환경변수가 설정되지 않았습니다. DB_SERVER, DB_USER, DB_PASSWORD, DB_NAME, API_KEY를 확인하세요.")
+	sort, err := parseBookSort(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
 	}
 
-	return config
-}
+	page, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if page.Keyset {
+		// 키셋 페이지는 id 순서로만 이어지고, 다음 커서를 만들려면 id가 필요
+		if sort != (bookSort{Column: "id"}) {
+			writeError(w, r, http.StatusBadRequest, "after는 id 오름차순 정렬에서만 사용할 수 있습니다", nil)
+			return
+		}
+		if len(filter.Fields) > 0 && !slices.Contains(filter.Fields, "id") {
+			filter.Fields = append(append([]string(nil), filter.Fields...), "id")
+		}
+	}
 
-// 환경변수 값 가져오기 (기본값 포함)
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	// 전체 건수는 X-Total-Count 헤더로 전달 (키셋 페이지에서도 after 이전 항목을 포함한 필터 전체 건수)
+	total, err := repo.Count(ctx, filter)
+	if err != nil {
+		requestLogger(r.Context()).Error("건수 조회 에러", "error", err)
+		writeDBError(w, r, err, "책 목록 조회 실패")
+		return
 	}
-	return defaultValue
-}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 
-// API 키 인증 미들웨어
-func authMiddleware(apiKey string) func(http.HandlerFunc) http.HandlerFunc {
-	return func(next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			requestAPIKey := r.Header.Get("X-API-Key")
-			if requestAPIKey == "" {
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(map[string]string{"error": "API 키가 필요합니다"})
-				return
-			}
+	// 키셋 페이지는 한 건 더 조회해 다음 페이지 유무를 판단 (limit 이하이므로 스트리밍하지 않음)
+	if page.Keyset {
+		probe := page
+		probe.Limit++
+		list, err := repo.List(ctx, filter, sort, probe)
+		if err != nil {
+			requestLogger(r.Context()).Error("조회 에러", "error", err)
+			writeDBError(w, r, err, "책 목록 조회 실패")
+			return
+		}
+		nextCursor := ""
+		if len(list) > page.Limit {
+			list = list[:page.Limit]
+			nextCursor = encodeBookCursor(list[len(list)-1].ID)
+		}
+		w.Header().Set("Link", page.keysetLinkHeader(r, nextCursor))
+		writeBookCursorPage(w, format, list, include, fields, nextCursor)
+		return
+	}
+	w.Header().Set("Link", page.linkHeader(r, total))
 
-			if requestAPIKey != apiKey {
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(map[string]string{"error": "유효하지 않은 API 키입니다"})
-				return
+	// 이번 페이지 결과가 STREAM_THRESHOLD보다 많으면 커서에서 바로 스트리밍
+	if appConfig.StreamThreshold > 0 {
+		pageCount := total - page.Offset
+		if pageCount > page.Limit {
+			pageCount = page.Limit
+		}
+		if pageCount > appConfig.StreamThreshold {
+			if err := streamBooks(ctx, w, format, filter, sort, page, include, fields); err != nil {
+				requestLogger(r.Context()).Error("조회 에러", "error", err)
+				writeDBError(w, r, err, "책 목록 조회 실패")
 			}
-
-			next.ServeHTTP(w, r)
+			return
 		}
 	}
+
+	list, err := repo.List(ctx, filter, sort, page)
+	if err != nil {
+		requestLogger(r.Context()).Error("조회 에러", "error", err)
+		writeDBError(w, r, err, "책 목록 조회 실패")
+		return
+	}
+	writeBookList(w, format, list, include, fields)
 }
 
-// DB 연결 함수
-func connectDB(config *Config) {
-	// MSSQL 연결 문자열
-	connString := fmt.Sprintf("server=%s;user id=%s;password=%s;port=%s;database=%s",
-		config.DBServer, config.DBUser, config.DBPassword, config.DBPort, config.DBName)
+// 책 수 조회 (목록과 같은 author/title/year 필터 적용)
+func GetBooksCount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-	// DB 연결
-	var err error
-	db, err = sql.Open("mssql", connString)
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseBookFilter(r)
 	if err != nil {
-		log.Fatal("DB 연결 실패:", err)
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
 	}
+	filter.Tenant = tenantFromContext(r.Context())
 
-	// 연결 테스트
-	err = db.Ping()
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	count, err := repo.Count(ctx, filter)
 	if err != nil {
-		log.Fatal("DB 연결 테스트 실패:", err)
+		requestLogger(r.Context()).Error("건수 조회 에러", "error", err)
+		writeDBError(w, r, err, "책 수 조회 실패")
+		return
 	}
 
-	log.Println("MSSQL DB 연결 성공!")
+	writeFormatted(w, format, struct {
+		Count int `json:"count" xml:"count"`
+	}{count}, "result", "")
 }
 
-type Book struct {
-	ID      string `json:"id,omitempty"`
-	Title   string `json:"title,omitempty"`
-	Author  string `json:"author,omitempty"`
-	Year    int    `json:"year,omitempty"`
-	Regdate string `json:"regdate,omitempty"`
-}
+// 출판 연도별 책 수 조회 (?from=&to=로 연도 범위 지정)
+func GetBookStatsByYear(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
 
-var books []Book
+	from, to, err := parseYearRange(r, "from", "to")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter := bookFilter{YearFrom: from, YearTo: to, Tenant: tenantFromContext(r.Context())}
 
-// 헬스체크 엔드포인트 추가
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-		"time":   time.Now().Format(time.RFC3339),
-	})
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	stats, err := repo.CountByYear(ctx, filter)
+	if err != nil {
+		requestLogger(r.Context()).Error("통계 조회 에러", "error", err)
+		writeDBError(w, r, err, "연도별 통계 조회 실패")
+		return
+	}
+
+	writeFormatted(w, format, stats, "stats", "year_count")
 }
 
-// 모든 책 정보 조회
-func GetBooks(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(books)
+// 책 목록 스트리밍 응답 (한 행씩 인코딩하여 결과 전체를 메모리에 올리지 않음)
+// 첫 행을 쓰기 전의 에러는 반환한다. 응답을 쓰기 시작한 뒤의 에러는 상태 코드를 바꿀 수 없으므로
+// 닫는 괄호를 쓰지 않고 연결을 끊어(http.ErrAbortHandler) 클라이언트가 잘린 목록을 정상 응답으로 받지 않게 한다.
+func streamBooks(ctx context.Context, w http.ResponseWriter, format string, filter bookFilter, sort bookSort, page pagination, include map[string]struct{}, fields []string) error {
+	open, sep, end := "[", ",", "]\n"
+	if format == formatXML {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		open, sep, end = xml.Header+"<books>", "", "</books>\n"
+	}
+
+	started := false
+	err := repo.Each(ctx, filter, sort, page, func(book Book) error {
+		var data []byte
+		var err error
+		if format == formatXML {
+			data, err = marshalXMLElement(bookListItem(book, include, fields), "book")
+		} else {
+			data, err = json.Marshal(bookListItem(book, include, fields))
+		}
+		if err != nil {
+			return err
+		}
+		if started {
+			w.Write([]byte(sep))
+		} else {
+			w.Write([]byte(open))
+			started = true
+		}
+		w.Write(data)
+		return nil
+	})
+	if err != nil && !started {
+		return err
+	}
+	if err != nil {
+		requestLogger(ctx).Error("스트리밍 조회 에러 (연결 종료)", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	if !started {
+		w.Write([]byte(open))
+	}
+	w.Write([]byte(end))
+	return nil
 }
 
-// 특정 ID의 책 정보 조회
+// 특정 ID의 책 정보 조회 (HEAD 요청이면 헤더만 응답)
 func GetBook(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	params := mux.Vars(r)
 	id := params["id"]
 
-	for _, book := range books {
-		if book.ID == id {
-			json.NewEncoder(w).Encode(book)
-			return
-		}
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	include, ok := includeParam(w, r)
+	if !ok {
+		return
+	}
+
+	includeDeleted, err := parseIncludeDeleted(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	book, err := repo.Get(ctx, id, includeDeleted)
+	if err == errBookNotFound {
+		// 책을 찾지 못한 경우
+		writeError(w, r, http.StatusNotFound, "책을 찾을 수 없습니다", nil)
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("조회 에러", "error", err)
+		writeDBError(w, r, err, "책 정보 조회 실패")
+		return
+	}
+
+	// 클라이언트가 가진 버전과 같으면 본문 없이 304
+	etag := bookETag(book)
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// HEAD는 GET과 같은 헤더(Content-Length 포함)만 보내고 본문은 쓰지 않음
+	if r.Method == http.MethodHead {
+		hw := &headResponseWriter{ResponseWriter: w}
+		writeFormatted(hw, format, bookResponse(book, include), "book", "")
+		w.Header().Set("Content-Length", strconv.Itoa(hw.length))
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
-	// 책을 찾지 못한 경우
-	w.WriteHeader(http.StatusNotFound)
-	json.NewEncoder(w).Encode(map[string]string{"error": "책을 찾을 수 없습니다"})
+	writeFormatted(w, format, bookResponse(book, include), "book", "")
+}
+
+// HEAD 응답용 ResponseWriter (본문은 버리고 길이만 기록)
+type headResponseWriter struct {
+	http.ResponseWriter
+	length int
+}
+
+func (hw *headResponseWriter) Write(b []byte) (int, error) {
+	hw.length += len(b)
+	return len(b), nil
 }
 
 // 새로운 책 추가
 func CreateBook(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+
+	include, ok := includeParam(w, r)
+	if !ok {
+		return
+	}
+
 	var book Book
-	err := json.NewDecoder(r.Body).Decode(&book)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "잘못된 요청 형식입니다"})
+	if !decodeJSONBody(w, r, &book, "잘못된 요청 형식입니다") {
 		return
 	}
 
-	// DB에 책 정보 추가
-	query := "INSERT INTO bz.dbo.tbl_book (title, author, year, regdate) VALUES (?, ?, ?, GETDATE())"
-	_, err = db.Exec(query, book.Title, book.Author, book.Year)
-	if err != nil {
-		log.Printf("DB 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "책 정보 추가 실패"})
+	if fieldErrors := validateBook(book); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
 		return
 	}
 
-	// 추가된 책 정보 조회
-	var newBook Book
-	err = db.QueryRow("SELECT TOP 1 id, title, author, year, regdate FROM bz.dbo.tbl_book ORDER BY regdate DESC").
-		Scan(&newBook.ID, &newBook.Title, &newBook.Author, &newBook.Year, &newBook.Regdate)
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	newBook, err := repo.Create(ctx, book)
+	if isRetryableWriteError(err) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusServiceUnavailable, "동시 처리 중인 쓰기 요청이 많습니다. 잠시 후 다시 시도하세요", nil)
+		return
+	}
+	if writeDuplicateError(w, r, err, nil) {
+		return
+	}
 	if err != nil {
-		log.Printf("조회 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "추가된 책 정보 조회 실패"})
+		requestLogger(r.Context()).Error("DB 에러", "error", err)
+		writeDBError(w, r, err, "책 정보 추가 실패")
 		return
 	}
 
-	books = append(books, newBook)
+	// 요청한 경로 기준 (/v1/books 또는 레거시 /books)
+	w.Header().Set("Location", externalPath(r.URL.Path)+"/"+newBook.ID)
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(newBook)
+	json.NewEncoder(w).Encode(bookResponse(newBook, include))
 }
 
 // 책 정보 수정
@@ -195,59 +1365,186 @@ func UpdateBook(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id := params["id"]
 
+	include, ok := includeParam(w, r)
+	if !ok {
+		return
+	}
+
 	var book Book
-	err := json.NewDecoder(r.Body).Decode(&book)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "잘못된 요청 형식입니다"})
+	if !decodeJSONBody(w, r, &book, "잘못된 요청 형식입니다") {
+		return
+	}
+
+	// 전체 수정은 조회 시 받은 version 또는 If-Match가 필수 (다른 요청의 수정을 덮어쓰지 않도록)
+	fieldErrors := validateBook(book)
+	if book.Version <= 0 && r.Header.Get("If-Match") == "" {
+		fieldErrors["version"] = "필수 항목입니다"
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
 		return
 	}
 
 	// DB에서 책 정보 수정
-	query := "UPDATE bz.dbo.tbl_book SET title = ?, author = ?, year = ? WHERE id = ?"
-	result, err := db.Exec(query, book.Title, book.Author, book.Year, id)
-	if err != nil {
-		log.Printf("DB 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "책 정보 수정 실패"})
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	// If-Match가 일치하면 그때의 버전으로 수정 (확인과 수정 사이의 변경은 버전 충돌로 처리됨)
+	current, ok := checkIfMatch(ctx, w, r, id)
+	if !ok {
 		return
 	}
+	if book.Version <= 0 {
+		book.Version = current.Version
+	}
 
-	// 수정된 행이 있는지 확인
-	rowsAffected, err := result.RowsAffected()
+	updatedBook, err := repo.Update(ctx, id, book)
+	if err == errBookNotFound {
+		writeError(w, r, http.StatusNotFound, "수정할 책을 찾을 수 없습니다", nil)
+		return
+	}
+	var conflict *versionConflictError
+	if errors.As(err, &conflict) {
+		writeVersionConflict(w, r, conflict)
+		return
+	}
+	if writeDuplicateError(w, r, err, nil) {
+		return
+	}
 	if err != nil {
-		log.Printf("행 수 확인 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "수정 결과 확인 실패"})
+		requestLogger(r.Context()).Error("DB 에러", "error", err)
+		writeDBError(w, r, err, "책 정보 수정 실패")
 		return
 	}
 
-	if rowsAffected == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "수정할 책을 찾을 수 없습니다"})
+	w.Header().Set("ETag", bookETag(updatedBook))
+	json.NewEncoder(w).Encode(bookResponse(updatedBook, include))
+}
+
+// 책 정보 부분 수정 (요청 본문에 있는 필드만 수정, PUT과 같이 If-Match를 확인)
+func PatchBook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := mux.Vars(r)
+	id := params["id"]
+
+	include, ok := includeParam(w, r)
+	if !ok {
 		return
 	}
 
-	// 수정된 책 정보 조회
-	var updatedBook Book
-	err = db.QueryRow("SELECT id, title, author, year, regdate FROM bz.dbo.tbl_book WHERE id = ?", id).
-		Scan(&updatedBook.ID, &updatedBook.Title, &updatedBook.Author, &updatedBook.Year, &updatedBook.Regdate)
-	if err != nil {
-		log.Printf("조회 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "수정된 책 정보 조회 실패"})
+	// 생략된 필드와 0 값으로 보낸 필드를 구분하기 위해 필드별 원본 JSON으로 디코딩
+	var raw map[string]json.RawMessage
+	if !decodeJSONBody(w, r, &raw, "잘못된 요청 형식입니다") {
+		return
+	}
+	known := map[string]struct{}{}
+	for _, f := range bookFieldDefs() {
+		known[f.Name] = struct{}{}
+	}
+	unknown := map[string]string{}
+	for name := range raw {
+		if _, ok := known[name]; !ok {
+			unknown[name] = "알 수 없는 필드입니다"
+		}
+	}
+	if len(unknown) > 0 {
+		writeValidationError(w, r, unknown)
 		return
 	}
 
-	// 메모리 데이터 업데이트
-	for i, b := range books {
-		if b.ID == id {
-			books[i] = updatedBook
-			break
+	var book Book
+	var patch bookPatch
+	for _, name := range []string{"title", "author", "year", "isbn"} {
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+		var err error
+		switch name {
+		case "title":
+			err = json.Unmarshal(value, &book.Title)
+			patch.Title = &book.Title
+		case "author":
+			err = json.Unmarshal(value, &book.Author)
+			patch.Author = &book.Author
+		case "year":
+			book.Year, err = parseYear(value, appConfig.LenientNumbers)
+			book.yearSet = true
+			patch.Year = &book.Year
+		case "isbn":
+			err = json.Unmarshal(value, &book.ISBN)
+			book.ISBN = normalizeISBN(book.ISBN)
+			patch.ISBN = &book.ISBN
+		}
+		if err != nil {
+			writeValidationError(w, r, map[string]string{name: jsonTypeMessage(err)})
+			return
+		}
+	}
+
+	if patch.Title == nil && patch.Author == nil && patch.Year == nil && patch.ISBN == nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "수정할 필드가 없습니다 (title, author, year, isbn)", nil)
+		return
+	}
+
+	// version을 보내면 현재 버전과 같을 때만 수정
+	if value, ok := raw["version"]; ok {
+		var version int
+		if err := json.Unmarshal(value, &version); err != nil {
+			writeValidationError(w, r, map[string]string{"version": jsonTypeMessage(err)})
+			return
+		}
+		patch.Version = &version
+	}
+
+	// 보낸 필드만 검증
+	fieldErrors := validateBook(book)
+	for name := range fieldErrors {
+		if _, ok := raw[name]; !ok {
+			delete(fieldErrors, name)
+		}
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	// DB에서 보낸 컬럼만 수정
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	// If-Match가 일치하면 그때의 버전으로 수정 (확인과 수정 사이의 변경은 버전 충돌로 처리됨)
+	if r.Header.Get("If-Match") != "" {
+		current, ok := checkIfMatch(ctx, w, r, id)
+		if !ok {
+			return
 		}
+		if patch.Version == nil {
+			patch.Version = &current.Version
+		}
+	}
+
+	patchedBook, err := repo.Patch(ctx, id, patch)
+	if err == errBookNotFound {
+		writeError(w, r, http.StatusNotFound, "수정할 책을 찾을 수 없습니다", nil)
+		return
+	}
+	var conflict *versionConflictError
+	if errors.As(err, &conflict) {
+		writeVersionConflict(w, r, conflict)
+		return
+	}
+	if writeDuplicateError(w, r, err, nil) {
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("DB 에러", "error", err)
+		writeDBError(w, r, err, "책 정보 수정 실패")
+		return
 	}
 
-	json.NewEncoder(w).Encode(updatedBook)
+	w.Header().Set("ETag", bookETag(patchedBook))
+	json.NewEncoder(w).Encode(bookResponse(patchedBook, include))
 }
 
 // 책 삭제
@@ -257,107 +1554,208 @@ func DeleteBook(w http.ResponseWriter, r *http.Request) {
 	id := params["id"]
 
 	// DB에서 책 삭제
-	query := "DELETE FROM bz.dbo.tbl_book WHERE id = ?"
-	result, err := db.Exec(query, id)
-	if err != nil {
-		log.Printf("DB 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "책 삭제 실패"})
+	ctx, cancel := dbContext(r.Context())
+	defer cancel()
+
+	// If-Match를 보냈으면 확인한 버전일 때만 삭제 (확인과 삭제 사이에 수정되면 412)
+	current, ok := checkIfMatch(ctx, w, r, id)
+	if !ok {
 		return
 	}
+	var expected *int
+	if r.Header.Get("If-Match") != "" {
+		expected = &current.Version
+	}
 
-	// 삭제된 행이 있는지 확인
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("행 수 확인 에러: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "삭제 결과 확인 실패"})
+	err := repo.Delete(ctx, id, expected)
+	if err == errBookNotFound {
+		writeError(w, r, http.StatusNotFound, "삭제할 책을 찾을 수 없습니다", nil)
 		return
 	}
-
-	if rowsAffected == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "삭제할 책을 찾을 수 없습니다"})
+	var conflict *versionConflictError
+	if errors.As(err, &conflict) {
+		writeError(w, r, http.StatusPreconditionFailed, "책이 그 사이 변경되었습니다 (If-Match 불일치)", nil)
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("DB 에러", "error", err)
+		writeDBError(w, r, err, "책 삭제 실패")
 		return
 	}
 
-	// 메모리에서도 삭제
-	for i, book := range books {
-		if book.ID == id {
-			books = append(books[:i], books[i+1:]...)
-			break
-		}
+	if appConfig.DeleteNoContent {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "책이 성공적으로 삭제되었습니다"})
 }
 
+// DB 연결 종료 (메모리 저장소 사용 시 연결 없음)
+func closeDB() {
+	if db != nil {
+		db.Close()
+	}
+}
+
 func main() {
 	// 설정 로드
-	config := loadConfig()
+	appConfig = loadConfig()
+	bookTable = appConfig.DBCatalog + "." + appConfig.DBSchema + "." + appConfig.DBTable
+	auditTable = bookTable + "_audit"
+	quotaTable = appConfig.DBCatalog + "." + appConfig.DBSchema + ".tbl_api_quota"
+	initLogger(appConfig.LogLevel)
+	log.Printf("버전 %s (커밋 %s, 빌드 %s)", Version, Commit, BuildTime)
 
-	// DB 연결
-	connectDB(config)
-	defer db.Close()
+	// 시작 점검 (필수 환경변수, DB 연결, 테이블 구조) - 치명적 항목 실패 시 종료
+	logStartupReport(runStartupChecks(appConfig))
 
-	// 테이블 구조 확인을 위한 쿼리
-	rows, err := db.Query("SELECT TOP 1 * FROM bz.dbo.tbl_book")
-	if err != nil {
-		log.Fatal("DB 조회 실패:", err)
+	// 책 저장소 선택
+	if appConfig.DBDriver == "memory" {
+		repo = newMemoryBookRepo()
+		log.Println("메모리 저장소 사용: DB 없이 실행하며 종료 시 데이터가 사라집니다")
+	} else {
+		repo = newMSSQLBookRepo(db)
 	}
-	defer rows.Close()
-
-	// 컬럼 정보 가져오기
-	columns, err := rows.Columns()
-	if err != nil {
-		log.Fatal("컬럼 정보 조회 실패:", err)
+	if multiTenant() {
+		log.Printf("멀티 테넌시 사용: 테넌트 %d개", len(appConfig.TenantKeys))
 	}
-	log.Printf("테이블 컬럼: %v", columns)
 
-	// 실제 데이터 조회
-	rows, err = db.Query("SELECT * FROM bz.dbo.tbl_book")
-	if err != nil {
-		log.Fatal("DB 조회 실패:", err)
+	// 쓰기 트랜잭션 동시 실행 제한
+	if appConfig.MaxConcurrentTx > 0 {
+		txSemaphore = make(chan struct{}, appConfig.MaxConcurrentTx)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var id, title, author string
-		var year int
-		var regdate time.Time
+	// 책 추가 쓰기 큐 (옵트인)
+	if appConfig.WriteQueueSize > 0 {
+		startInsertQueue(appConfig.WriteQueueSize, appConfig.WriteQueueWorkers, appConfig.WriteBatchMax, appConfig.WriteBatchWait)
+	}
 
-		err = rows.Scan(&id, &title, &author, &year, &regdate)
-		if err != nil {
-			log.Fatal("데이터 스캔 실패:", err)
+	// 라우터와 미들웨어 구성
+	handler := newHandler()
+	if appConfig.BasePath != "" {
+		log.Printf("기본 경로: %s (모든 경로가 이 접두사 아래에서 서비스됩니다)", appConfig.BasePath)
+	} else {
+		log.Println("기본 경로: 없음")
+	}
+	log.Printf("API 경로: %s/v1/books (접두사 없는 %s/books 경로는 레거시로 유지)", appConfig.BasePath, appConfig.BasePath)
+	srv := &http.Server{
+		Addr:      ":" + appConfig.Port,
+		Handler:   inFlightMiddleware(requestLogMiddleware(handler)),
+		TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	go func() {
+		var err error
+		if appConfig.TLSCertFile != "" {
+			log.Printf("서버가 포트 %s에서 시작됩니다 (HTTPS)...", appConfig.Port)
+			err = srv.ListenAndServeTLS(appConfig.TLSCertFile, appConfig.TLSKeyFile)
+		} else {
+			log.Printf("서버가 포트 %s에서 시작됩니다 ...", appConfig.Port)
+			err = srv.ListenAndServe()
 		}
-
-		book := Book{
-			ID:      id,
-			Title:   title,
-			Author:  author,
-			Year:    year,
-			Regdate: regdate.Format("2006-01-02 15:04:05"),
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
 		}
-		books = append(books, book)
+	}()
+
+	// 종료 시그널 대기 후 진행 중인 요청을 마저 처리하고 종료
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	// /ready가 바로 503을 반환하도록 먼저 표시
+	draining.Store(true)
+	log.Printf("shutting down gracefully: 진행 중인 요청 %d건 처리 후 종료합니다 (최대 %s)", inFlightRequests.Load(), appConfig.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("요청 처리 대기 시간 초과, 처리 중인 요청 %d건의 연결을 강제 종료합니다: %v", inFlightRequests.Load(), err)
+		srv.Close()
+		closeDB()
+		os.Exit(1)
 	}
+	closeDB()
+	log.Printf("서버 종료 완료 (남은 요청 %d건)", inFlightRequests.Load())
+}
 
+// 라우트와 미들웨어를 모두 구성한 HTTP 핸들러 (appConfig와 repo 설정 후 호출)
+func newHandler() http.Handler {
 	// 인증 미들웨어 생성
-	auth := authMiddleware(config.APIKey)
+	auth := authMiddleware(appConfig.APIKeys, appConfig.TenantKeys, newJWTVerifier(appConfig.JWTSecret, appConfig.JWTPublicKey))
+
+	// 레거시 라우트 폐기 안내 미들웨어 생성
+	deprecated := deprecationMiddleware(appConfig.DeprecationDate, appConfig.SunsetDate)
+
+	// 쓰기 할당량 미들웨어 생성 (쓰기 라우트에만 적용)
+	quota := quotaMiddleware(appConfig.WriteQuotaPerDay)
+
+	// API 키별 요청 속도 제한 미들웨어 생성 (인증 후 적용, RATE_LIMIT_ROUTES의 라우트는 라우트별 한도)
+	limit := rateLimitMiddleware(appConfig.RateLimitRPS, appConfig.RateLimitBurst, appConfig.RateLimitRoutes)
+
+	// 책 추가 Idempotency-Key 미들웨어 생성 (/v1과 레거시 경로가 같은 저장소를 공유)
+	idempotent := idempotencyMiddleware(appConfig.IdempotencyTTL)
 
 	router := mux.NewRouter()
 
 	// 헬스체크 엔드포인트 (인증 불필요)
-	router.HandleFunc("/health", HealthCheck).Methods("GET")
-
-	// API 엔드포인트들
-	router.HandleFunc("/books", auth(GetBooks)).Methods("GET")
-	router.HandleFunc("/books/{id}", auth(GetBook)).Methods("GET")
-	router.HandleFunc("/books", auth(CreateBook)).Methods("POST")
-	router.HandleFunc("/books/{id}", auth(UpdateBook)).Methods("PUT")
-	router.HandleFunc("/books/{id}", auth(DeleteBook)).Methods("DELETE")
-
-	// 서버 시작
-	log.Printf("서버가 포트 %s에서 시작됩니다...", config.Port)
-	log.Fatal(http.ListenAndServe(":"+config.Port, router))
+	router.HandleFunc("/health", HealthCheck).Methods("GET").Name("HealthCheck")
+	router.HandleFunc("/ready", ReadyCheck).Methods("GET").Name("ReadyCheck")
+	router.HandleFunc("/healthz/detail", HealthDetail).Methods("GET").Name("HealthDetail")
+	router.HandleFunc("/version", GetVersion).Methods("GET").Name("GetVersion")
+
+	// Prometheus 메트릭 (인증 불필요)
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET").Name("Metrics")
+
+	// 책 API (/v1 접두사, 새 버전은 router.PathPrefix("/v2").Subrouter()로 나란히 추가)
+	// 책 추가는 재현한 응답이 할당량을 쓰지 않도록 Idempotency-Key 확인을 할당량보다 먼저 적용
+	// JWT 요청은 조회는 인증만, 쓰기는 JWT_WRITE_SCOPE 스코프를 요구 (JWT_ROUTE_SCOPES로 라우트별 변경)
+	// 드라이런 응답이 Idempotency-Key로 저장되지 않도록 드라이런 확인을 먼저 적용
+	readScope := scopeMiddleware("", appConfig.JWTRouteScopes)
+	writeScope := scopeMiddleware(appConfig.JWTWriteScope, appConfig.JWTRouteScopes)
+	read := chain(auth, readScope, limit)
+	write := chain(auth, writeScope, limit, dryRunMiddleware, quota)
+	create := chain(auth, writeScope, limit, dryRunMiddleware, idempotent, quota)
+	registerBookRoutes(router.PathPrefix("/v1").Subrouter(), read, write, create)
+
+	// 운영 진단용 DB 연결 풀 상태 (API 키 필요)
+	router.HandleFunc("/admin/db-stats", read(GetDBStats)).Methods("GET").Name("GetDBStats")
+
+	// 접두사 없는 레거시 라우트 (기존 클라이언트용, Deprecation/Sunset 헤더 대상)
+	registerBookRoutes(router, chain(deprecated, read), chain(deprecated, write), chain(deprecated, create))
+
+	// JWT_ROUTE_SCOPES, RATE_LIMIT_ROUTES 라우트 이름 확인 (오타로 권한 설정이 조용히 무시되지 않도록)
+	for name := range appConfig.JWTRouteScopes {
+		if router.Get(name) == nil {
+			log.Fatalf("JWT_ROUTE_SCOPES에 알 수 없는 라우트 이름이 있습니다: %q", name)
+		}
+	}
+	for name := range appConfig.RateLimitRoutes {
+		if router.Get(name) == nil {
+			log.Fatalf("RATE_LIMIT_ROUTES에 알 수 없는 라우트 이름이 있습니다: %q", name)
+		}
+	}
+
+	// 요청 수/처리 시간 메트릭 (라우트 템플릿 기준)
+	router.Use(metricsMiddleware)
+
+	// 요청별 기능 플래그 (허용 목록에 있는 플래그만 적용)
+	router.Use(featureFlagsMiddleware(appConfig.FeatureFlags))
+
+	// 디버그 모드: 처리한 핸들러 이름을 X-Handler 헤더로 노출
+	if appConfig.Debug {
+		router.Use(debugHandlerMiddleware)
+	}
+
+	// CORS 프리플라이트는 정규 호스트 리다이렉트와 라우터보다 먼저 처리
+	handler := canonicalHostMiddleware(appConfig.CanonicalHost, appConfig.CanonicalHostUseForwarded)(router)
+	handler = corsMiddleware(appConfig.AllowedOrigins)(handler)
+	handler = gzipMiddleware(handler)
+	// 헬스체크와 메트릭을 포함한 모든 경로를 BASE_PATH 아래로 (라우터에는 접두사를 뗀 경로가 전달됨)
+	handler = basePathMiddleware(appConfig.BasePath)(handler)
+	// 처리 제한 시간 초과 응답도 요청 로그에 남도록 요청 로그 미들웨어 안쪽에 적용
+	handler = timeoutMiddleware(appConfig.RequestTimeout)(handler)
+	return handler
 }
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 테스트용 API 키
+const testAPIKey = "test-key-0123456789abcdef"
+
+// 메모리 저장소로 전체 라우터와 미들웨어를 구성한 테스트 핸들러
+// env로 기본 설정 외의 환경변수를 지정한다.
+func newTestHandler(t *testing.T, env map[string]string) http.Handler {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("API_KEY", testAPIKey)
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	appConfig = loadConfig()
+	repo = newMemoryBookRepo()
+	return newHandler()
+}
+
+// 테스트 요청 실행 (헤더를 지정하지 않으면 테스트 API 키로 인증)
+func doRequest(t *testing.T, h http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if header == nil {
+		header = http.Header{"X-Api-Key": {testAPIKey}}
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// 응답 본문을 v로 해석
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("응답 본문 해석 실패: %v (%s)", err, rec.Body.String())
+	}
+}
+
+// 책 추가 후 응답의 책 반환
+func createTestBook(t *testing.T, h http.Handler, body string) Book {
+	t.Helper()
+	rec := doRequest(t, h, "POST", "/v1/books", body, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("책 추가 상태 코드 = %d, 원하는 값 201 (%s)", rec.Code, rec.Body.String())
+	}
+	var book Book
+	decodeResponse(t, rec, &book)
+	return book
+}
+
+func TestBookCRUD(t *testing.T) {
+	h := newTestHandler(t, nil)
+
+	created := createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	if created.ID == "" || created.Version != 1 {
+		t.Fatalf("추가한 책 = %+v", created)
+	}
+
+	rec := doRequest(t, h, "GET", "/v1/books/"+created.ID, "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("조회 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var got Book
+	decodeResponse(t, rec, &got)
+	if got != created {
+		t.Errorf("조회한 책 = %+v, 원하는 값 %+v", got, created)
+	}
+
+	rec = doRequest(t, h, "PUT", "/v1/books/"+created.ID, `{"title":"토지 1부","author":"박경리","year":1969,"version":1}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("수정 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var updated Book
+	decodeResponse(t, rec, &updated)
+	if updated.Title != "토지 1부" || updated.Version != 2 {
+		t.Errorf("수정한 책 = %+v", updated)
+	}
+
+	rec = doRequest(t, h, "DELETE", "/v1/books/"+created.ID, "", nil)
+	if rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+		t.Fatalf("삭제 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, h, "GET", "/v1/books/"+created.ID, "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("삭제 후 조회 상태 코드 = %d, 원하는 값 404", rec.Code)
+	}
+}
+
+func TestGetBooksList(t *testing.T) {
+	h := newTestHandler(t, nil)
+	createTestBook(t, h, `{"title":"채식주의자","author":"한강","year":2007}`)
+	createTestBook(t, h, `{"title":"소년이 온다","author":"한강","year":2014}`)
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("목록 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var books []Book
+	decodeResponse(t, rec, &books)
+	if len(books) != 2 {
+		t.Errorf("목록 길이 = %d, 원하는 값 2", len(books))
+	}
+}
+
+func TestCreateBookValidation(t *testing.T) {
+	h := newTestHandler(t, nil)
+
+	rec := doRequest(t, h, "POST", "/v1/books", `{"title":"","author":"한강","year":2007}`, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("상태 코드 = %d, 원하는 값 422 (%s)", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Fields map[string]string `json:"fields"`
+	}
+	decodeResponse(t, rec, &body)
+	if _, ok := body.Fields["title"]; !ok {
+		t.Errorf("fields에 title이 없습니다: %v", body.Fields)
+	}
+}
+
+func TestGetBookNotFound(t *testing.T) {
+	h := newTestHandler(t, nil)
+	rec := doRequest(t, h, "GET", "/v1/books/999", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("상태 코드 = %d, 원하는 값 404", rec.Code)
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"key-a", []string{"key-a"}},
+		{"key-a, key-b ,key-a", []string{"key-a", "key-b"}},
+	}
+	for _, tt := range tests {
+		got := parseAPIKeys(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseAPIKeys(%q) = %v, 원하는 값 %v", tt.value, got, tt.want)
+			continue
+		}
+		for _, key := range tt.want {
+			if _, ok := got[key]; !ok {
+				t.Errorf("parseAPIKeys(%q)에 %q가 없습니다", tt.value, key)
+			}
+		}
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"API_KEY": testAPIKey + ",second-key-0123456789"})
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"첫 번째 키", http.Header{"X-Api-Key": {testAPIKey}}, http.StatusOK},
+		{"두 번째 키", http.Header{"X-Api-Key": {"second-key-0123456789"}}, http.StatusOK},
+		{"잘못된 키", http.Header{"X-Api-Key": {"wrong-key-0123456789"}}, http.StatusUnauthorized},
+		{"키 앞부분만 일치", http.Header{"X-Api-Key": {testAPIKey[:8]}}, http.StatusUnauthorized},
+		{"키 없음", http.Header{}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, h, "GET", "/v1/books", "", tt.header)
+		if rec.Code != tt.want {
+			t.Errorf("%s: 상태 코드 = %d, 원하는 값 %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestStartupChecksRequireAPIKey(t *testing.T) {
+	report := runStartupChecks(&Config{DBDriver: "memory", APIKeys: parseAPIKeys(" , ")})
+	if report.OK {
+		t.Fatal("API_KEY가 비어 있는데 시작 점검이 통과했습니다")
+	}
+	if report.Checks[0].Name != "env" || report.Checks[0].Status != "fail" {
+		t.Errorf("env 점검 = %+v, 원하는 상태 fail", report.Checks[0])
+	}
+
+	report = runStartupChecks(&Config{DBDriver: "memory", APIKeys: parseAPIKeys(testAPIKey)})
+	if !report.OK {
+		t.Errorf("API_KEY가 있는데 시작 점검이 실패했습니다: %+v", report.Checks)
+	}
+}
+
+func TestPatchBookYearOnly(t *testing.T) {
+	h := newTestHandler(t, nil)
+	created := createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969,"isbn":"9780306406157"}`)
+
+	rec := doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"year":1973}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("year 부분 수정 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var patched Book
+	decodeResponse(t, rec, &patched)
+	if patched.Year != 1973 || patched.Version != created.Version+1 {
+		t.Errorf("부분 수정한 책 = %+v", patched)
+	}
+	if patched.Title != created.Title || patched.Author != created.Author || patched.ISBN != created.ISBN {
+		t.Errorf("보내지 않은 필드가 바뀌었습니다: %+v, 원래 값 %+v", patched, created)
+	}
+
+	// year를 생략하면 저장된 year를 유지
+	rec = doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"title":"토지 1부"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("title 부분 수정 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	decodeResponse(t, rec, &patched)
+	if patched.Title != "토지 1부" || patched.Year != 1973 {
+		t.Errorf("year를 생략한 부분 수정 후 책 = %+v", patched)
+	}
+
+	// year를 0으로 보내면 생략과 달리 검증 실패
+	rec = doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"year":0}`, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("year 0 부분 수정 상태 코드 = %d, 원하는 값 422 (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+// go test -race로 실행해 저장소 동기화 확인
+func TestConcurrentCreateAndDelete(t *testing.T) {
+	h := newTestHandler(t, nil)
+	const n = 20
+	var seeded []Book
+	for i := 0; i < n; i++ {
+		seeded = append(seeded, createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if rec := doRequest(t, h, "POST", "/v1/books", `{"title":"파친코","author":"이민진","year":2017}`, nil); rec.Code != http.StatusCreated {
+				t.Errorf("추가 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+			}
+		}()
+		go func(id string) {
+			defer wg.Done()
+			if rec := doRequest(t, h, "DELETE", "/v1/books/"+id, "", nil); rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+				t.Errorf("삭제 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+			}
+		}(seeded[i].ID)
+		go func() {
+			defer wg.Done()
+			if rec := doRequest(t, h, "GET", "/v1/books", "", nil); rec.Code != http.StatusOK {
+				t.Errorf("목록 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", nil)
+	var books []Book
+	decodeResponse(t, rec, &books)
+	if len(books) != n {
+		t.Errorf("남은 책 수 = %d, 원하는 값 %d", len(books), n)
+	}
+	for _, book := range books {
+		if book.Title != "파친코" {
+			t.Errorf("삭제되지 않은 책이 남았습니다: %+v", book)
+		}
+	}
+}
+
+// 동시에 추가한 책은 각자 자기 id를 응답으로 받아야 함
+func TestConcurrentCreateDistinctIDs(t *testing.T) {
+	h := newTestHandler(t, nil)
+	const n = 10
+	responses := make([]Book, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"title":"책 %d","author":"저자 %d","year":%d}`, i, i, 2000+i)
+			rec := doRequest(t, h, "POST", "/v1/books", body, nil)
+			if rec.Code != http.StatusCreated {
+				t.Errorf("추가 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+				return
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &responses[i]); err != nil {
+				t.Errorf("응답 본문 해석 실패: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i, book := range responses {
+		if book.Title != fmt.Sprintf("책 %d", i) || book.Year != 2000+i {
+			t.Errorf("요청 %d의 응답이 다른 요청의 책입니다: %+v", i, book)
+		}
+		if seen[book.ID] {
+			t.Errorf("id %s가 중복되었습니다", book.ID)
+		}
+		seen[book.ID] = true
+
+		rec := doRequest(t, h, "GET", "/v1/books/"+book.ID, "", nil)
+		var stored Book
+		decodeResponse(t, rec, &stored)
+		if stored.Title != book.Title {
+			t.Errorf("id %s로 저장된 책 = %+v, 응답한 책 %+v", book.ID, stored, book)
+		}
+	}
+}
+
+func TestCreateBookLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		basePath string
+		path     string
+		want     string
+	}{
+		{"v1", "", "/v1/books", "/v1/books/"},
+		{"레거시 경로", "", "/books", "/books/"},
+		{"BASE_PATH", "/api", "/api/v1/books", "/api/v1/books/"},
+	}
+	for _, tt := range tests {
+		h := newTestHandler(t, map[string]string{"BASE_PATH": tt.basePath})
+		rec := doRequest(t, h, "POST", tt.path, `{"title":"토지","author":"박경리","year":1969}`, nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%s: 상태 코드 = %d, 원하는 값 201 (%s)", tt.name, rec.Code, rec.Body.String())
+		}
+		var book Book
+		decodeResponse(t, rec, &book)
+		if got := rec.Header().Get("Location"); got != tt.want+book.ID {
+			t.Errorf("%s: Location = %q, 원하는 값 %q", tt.name, got, tt.want+book.ID)
+		}
+
+		// Location이 가리키는 책을 그대로 조회할 수 있어야 함
+		rec = doRequest(t, h, "GET", rec.Header().Get("Location"), "", nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: Location 조회 상태 코드 = %d", tt.name, rec.Code)
+		}
+	}
+}
+
+// 값이 0이거나 비어 있어도 모든 필드가 같은 키로 응답되어야 함
+func TestBookResponseShape(t *testing.T) {
+	h := newTestHandler(t, nil)
+	created := createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	wantKeys := []string{"author", "id", "isbn", "regdate", "title", "updated_at", "version", "year"}
+
+	checkKeys := func(name string, book map[string]interface{}) {
+		t.Helper()
+		var keys []string
+		for key := range book {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if !reflect.DeepEqual(keys, wantKeys) {
+			t.Errorf("%s 응답 키 = %v, 원하는 값 %v", name, keys, wantKeys)
+		}
+	}
+
+	rec := doRequest(t, h, "GET", "/v1/books/"+created.ID, "", nil)
+	var single map[string]interface{}
+	decodeResponse(t, rec, &single)
+	checkKeys("단건 조회", single)
+	if single["isbn"] != "" {
+		t.Errorf("isbn = %v, 원하는 값 빈 문자열", single["isbn"])
+	}
+
+	rec = doRequest(t, h, "GET", "/v1/books", "", nil)
+	var list []map[string]interface{}
+	decodeResponse(t, rec, &list)
+	if len(list) != 1 {
+		t.Fatalf("목록 길이 = %d, 원하는 값 1", len(list))
+	}
+	checkKeys("목록 조회", list[0])
+
+	// 0 값 직렬화 (omitempty로 키가 빠지지 않음)
+	data, err := json.Marshal(Book{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zero map[string]interface{}
+	if err := json.Unmarshal(data, &zero); err != nil {
+		t.Fatal(err)
+	}
+	checkKeys("0 값 책", zero)
+	if zero["year"] != float64(0) || zero["author"] != "" {
+		t.Errorf("0 값 책 = %s", data)
+	}
+}
+
+// API_KEY에서 뺀 키는 API_KEY_TENANTS에 남아 있어도 인증되지 않아야 함
+func TestRevokedTenantKey(t *testing.T) {
+	const revoked = "revoked-key-0123456789"
+	h := newTestHandler(t, map[string]string{
+		"API_KEY_TENANTS": testAPIKey + ":acme," + revoked + ":acme",
+	})
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", http.Header{"X-Api-Key": {revoked}})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("API_KEY에 없는 테넌트 키 상태 코드 = %d, 원하는 값 401", rec.Code)
+	}
+	rec = doRequest(t, h, "GET", "/v1/books", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("API_KEY에 있는 테넌트 키 상태 코드 = %d, 원하는 값 200 (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+// 첫 책을 넘긴 뒤 커서 에러를 내는 저장소
+type failingEachRepo struct {
+	BookRepository
+}
+
+func (r failingEachRepo) Each(ctx context.Context, filter bookFilter, sort bookSort, page pagination, fn func(Book) error) error {
+	sent := 0
+	err := r.BookRepository.Each(ctx, filter, sort, page, func(book Book) error {
+		if sent == 1 {
+			return errors.New("커서 에러")
+		}
+		sent++
+		return fn(book)
+	})
+	return err
+}
+
+// 스트리밍 중 에러가 나면 닫는 괄호를 쓰지 않고 연결을 끊어야 함
+func TestStreamBooksAbortsOnMidStreamError(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"STREAM_THRESHOLD": "1"})
+	for i := 0; i < 3; i++ {
+		createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	}
+	repo = failingEachRepo{repo}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/books", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Fatalf("패닉 = %v, 원하는 값 http.ErrAbortHandler", p)
+		}
+		if body := rec.Body.String(); strings.HasSuffix(strings.TrimSpace(body), "]") {
+			t.Errorf("잘린 목록이 닫혔습니다: %s", body)
+		}
+	}()
+	h.ServeHTTP(rec, req)
+}
+
+func TestPatchBookIfMatch(t *testing.T) {
+	h := newTestHandler(t, nil)
+	created := createTestBook(t, h, `{"title":"토지","author":"박경리","year":1969}`)
+	rec := doRequest(t, h, "GET", "/v1/books/"+created.ID, "", nil)
+	staleETag := rec.Header().Get("ETag")
+	if staleETag == "" {
+		t.Fatal("ETag 헤더가 없습니다")
+	}
+
+	ifMatch := func(etag string) http.Header {
+		return http.Header{"X-Api-Key": {testAPIKey}, "If-Match": {etag}}
+	}
+	rec = doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"year":1973}`, ifMatch(staleETag))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("일치하는 If-Match 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	// 이전 ETag로 보낸 부분 수정은 덮어쓰지 않고 412
+	rec = doRequest(t, h, "PATCH", "/v1/books/"+created.ID, `{"title":"토지 1부"}`, ifMatch(staleETag))
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("이전 If-Match 상태 코드 = %d, 원하는 값 412 (%s)", rec.Code, rec.Body.String())
+	}
+	var book Book
+	decodeResponse(t, doRequest(t, h, "GET", "/v1/books/"+created.ID, "", nil), &book)
+	if book.Title != "토지" || book.Version != 2 {
+		t.Errorf("412 후 책 = %+v", book)
+	}
+}
+
+// year를 생략한 것과 0을 보낸 것은 다른 사유로 거부
+func TestCreateBookYearMissingVersusZero(t *testing.T) {
+	h := newTestHandler(t, nil)
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"생략", `{"title":"토지","author":"박경리"}`, "필수 항목입니다"},
+		{"null", `{"title":"토지","author":"박경리","year":null}`, "필수 항목입니다"},
+		{"0", `{"title":"토지","author":"박경리","year":0}`, fmt.Sprintf("1000 이상 %d 이하여야 합니다", time.Now().Year()+1)},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, h, "POST", "/v1/books", tt.body, nil)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: 상태 코드 = %d, 원하는 값 422", tt.name, rec.Code)
+			continue
+		}
+		var body struct {
+			Fields map[string]string `json:"fields"`
+		}
+		decodeResponse(t, rec, &body)
+		if body.Fields["year"] != tt.want {
+			t.Errorf("%s: year 사유 = %q, 원하는 값 %q", tt.name, body.Fields["year"], tt.want)
+		}
+	}
+}
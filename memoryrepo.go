@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 메모리 책 저장소 항목
+type memoryBookRecord struct {
+	book    Book
+	tenant  string
+	deleted bool // SOFT_DELETE 사용 시 삭제 표시
+}
+
+// 메모리 책 저장소 (DB_DRIVER=memory, DB 없이 CI/데모/로컬 실행용)
+// 입력값 검증은 MSSQL 저장소와 마찬가지로 핸들러에서 저장소를 호출하기 전에 한다.
+// 프로세스가 종료되면 데이터는 사라진다.
+type memoryBookRepo struct {
+	mu     sync.Mutex
+	nextID int
+	items  map[string]memoryBookRecord
+
+	// 변경 이력 (기록 순서, 책 변경과 같은 잠금 안에서 추가)
+	audit []memoryAuditRecord
+}
+
+// 메모리 저장소 변경 이력 항목
+type memoryAuditRecord struct {
+	entry  auditEntry
+	tenant string
+}
+
+// 메모리 책 저장소 생성
+func newMemoryBookRepo() *memoryBookRepo {
+	return &memoryBookRepo{items: map[string]memoryBookRecord{}}
+}
+
+// 필터 조건 일치 여부 (bookFilter.where와 같은 의미)
+func (f bookFilter) matches(record memoryBookRecord) bool {
+	if f.Tenant != "" && record.tenant != f.Tenant {
+		return false
+	}
+	if record.deleted && !f.IncludeDeleted {
+		return false
+	}
+	if len(f.Authors) > 0 {
+		found := false
+		for _, author := range f.Authors {
+			if strings.EqualFold(record.book.Author, author) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Author != "" && !strings.Contains(strings.ToLower(record.book.Author), strings.ToLower(f.Author)) {
+		return false
+	}
+	if f.AuthorExact != "" && !strings.EqualFold(record.book.Author, f.AuthorExact) {
+		return false
+	}
+	if f.Title != "" && !strings.Contains(strings.ToLower(record.book.Title), strings.ToLower(f.Title)) {
+		return false
+	}
+	if f.Year != 0 && record.book.Year != f.Year {
+		return false
+	}
+	if f.YearFrom != 0 && record.book.Year < f.YearFrom {
+		return false
+	}
+	if f.YearTo != 0 && record.book.Year > f.YearTo {
+		return false
+	}
+	return true
+}
+
+// 정렬 기준 비교 (bookSort.orderBy와 같은 순서, 동률이면 id 오름차순)
+func (s bookSort) less(a, b Book) bool {
+	idA, _ := strconv.Atoi(a.ID)
+	idB, _ := strconv.Atoi(b.ID)
+
+	var cmp int
+	switch s.Column {
+	case "title":
+		cmp = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "author":
+		cmp = strings.Compare(strings.ToLower(a.Author), strings.ToLower(b.Author))
+	case "year":
+		cmp = a.Year - b.Year
+	case "regdate":
+		cmp = strings.Compare(a.Regdate, b.Regdate)
+	default:
+		cmp = idA - idB
+	}
+	if s.Desc {
+		cmp = -cmp
+	}
+	if cmp != 0 {
+		return cmp < 0
+	}
+	return idA < idB
+}
+
+// 필터/정렬/페이지 범위를 적용한 목록 (잠금을 잡은 상태에서 호출)
+func (m *memoryBookRepo) selectLocked(filter bookFilter, order bookSort, page pagination) []Book {
+	var result []Book
+	for _, record := range m.items {
+		if id, _ := strconv.Atoi(record.book.ID); id <= page.AfterID {
+			continue
+		}
+		if filter.matches(record) {
+			result = append(result, record.book)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return order.less(result[i], result[j]) })
+
+	if page.Offset >= len(result) {
+		return []Book{}
+	}
+	result = result[page.Offset:]
+	if page.Limit > 0 && len(result) > page.Limit {
+		result = result[:page.Limit]
+	}
+	return result
+}
+
+// 현재 요청 테넌트의 항목 조회 (잠금을 잡은 상태에서 호출)
+func (m *memoryBookRepo) lookupLocked(ctx context.Context, id string, includeDeleted bool) (memoryBookRecord, bool) {
+	record, ok := m.items[id]
+	if !ok || record.tenant != tenantFromContext(ctx) || (record.deleted && !includeDeleted) {
+		return memoryBookRecord{}, false
+	}
+	return record, true
+}
+
+// 쓰기 대상 저장소 (드라이런이면 현재 상태의 복사본이라 결과만 계산하고 버림)
+func (m *memoryBookRepo) writeTarget(ctx context.Context) *memoryBookRepo {
+	if !isDryRun(ctx) {
+		return m
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scratch := &memoryBookRepo{nextID: m.nextID, items: make(map[string]memoryBookRecord, len(m.items))}
+	for id, record := range m.items {
+		scratch.items[id] = record
+	}
+	return scratch
+}
+
+// 새 항목 추가 (잠금을 잡은 상태에서 호출)
+func (m *memoryBookRepo) insertLocked(ctx context.Context, book Book) Book {
+	m.nextID++
+	book.ID = strconv.Itoa(m.nextID)
+	book.Regdate = formatTimestamp(time.Now())
+	book.UpdatedAt = book.Regdate
+	book.Version = 1
+	m.items[book.ID] = memoryBookRecord{book: book, tenant: tenantFromContext(ctx)}
+	m.auditLocked(ctx, auditCreate, nil, &book)
+	return book
+}
+
+// 변경 이력 추가 (잠금을 잡은 상태에서 호출)
+func (m *memoryBookRepo) auditLocked(ctx context.Context, operation string, oldBook, newBook *Book) {
+	source := auditSourceFromContext(ctx)
+	entry := auditEntry{
+		ID:        int64(len(m.audit) + 1),
+		Operation: operation,
+		OldValues: oldBook,
+		NewValues: newBook,
+		Actor:     source.Actor,
+		ChangedAt: formatTimestamp(time.Now()),
+	}
+	if oldBook != nil {
+		entry.BookID = oldBook.ID
+	} else {
+		entry.BookID = newBook.ID
+	}
+	m.audit = append(m.audit, memoryAuditRecord{entry: entry, tenant: source.Tenant})
+}
+
+// 책 목록 조회
+func (m *memoryBookRepo) List(ctx context.Context, filter bookFilter, sort bookSort, page pagination) ([]Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.selectLocked(filter, sort, page), nil
+}
+
+// 책 목록을 한 건씩 전달 (잠금 밖에서 fn 호출)
+func (m *memoryBookRepo) Each(ctx context.Context, filter bookFilter, sort bookSort, page pagination, fn func(Book) error) error {
+	m.mu.Lock()
+	list := m.selectLocked(filter, sort, page)
+	m.mu.Unlock()
+
+	for _, book := range list {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 필터 조건에 맞는 책 수 조회
+func (m *memoryBookRepo) Count(ctx context.Context, filter bookFilter) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, record := range m.items {
+		if filter.matches(record) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// 출판 연도별 책 수 (연도 오름차순)
+func (m *memoryBookRepo) CountByYear(ctx context.Context, filter bookFilter) ([]yearCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := map[int]int{}
+	for _, record := range m.items {
+		if filter.matches(record) {
+			counts[record.book.Year]++
+		}
+	}
+	result := make([]yearCount, 0, len(counts))
+	for year, count := range counts {
+		result = append(result, yearCount{Year: year, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Year < result[j].Year })
+	return result, nil
+}
+
+// 특정 ID의 책 조회
+func (m *memoryBookRepo) Get(ctx context.Context, id string, includeDeleted bool) (Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.lookupLocked(ctx, id, includeDeleted)
+	if !ok {
+		return Book{}, errBookNotFound
+	}
+	return record.book, nil
+}
+
+// 다른 책이 이미 쓰는 ISBN인지 확인 (isbn 고유 인덱스와 같이 테넌트/삭제 여부와 무관, 잠금을 잡은 상태에서 호출)
+func (m *memoryBookRepo) isbnTakenLocked(isbn, exceptID string) bool {
+	if isbn == "" {
+		return false
+	}
+	for id, record := range m.items {
+		if id != exceptID && record.book.ISBN == isbn {
+			return true
+		}
+	}
+	return false
+}
+
+// 책 추가 (ID 자동 증가, regdate/updated_at은 현재 시각)
+func (m *memoryBookRepo) Create(ctx context.Context, book Book) (Book, error) {
+	m = m.writeTarget(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isbnTakenLocked(book.ISBN, "") {
+		return Book{}, &duplicateKeyError{Field: "isbn"}
+	}
+	return m.insertLocked(ctx, book), nil
+}
+
+// 여러 책 추가 (잠금 안에서 모두 확인한 뒤 추가하므로 부분 반영 없음)
+func (m *memoryBookRepo) CreateBatch(ctx context.Context, batch []Book) ([]Book, error) {
+	m = m.writeTarget(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := map[string]struct{}{}
+	for i, book := range batch {
+		if _, dup := seen[book.ISBN]; dup || m.isbnTakenLocked(book.ISBN, "") {
+			return nil, &batchRowError{Index: i, Err: &duplicateKeyError{Field: "isbn"}}
+		}
+		if book.ISBN != "" {
+			seen[book.ISBN] = struct{}{}
+		}
+	}
+
+	created := make([]Book, 0, len(batch))
+	for _, book := range batch {
+		created = append(created, m.insertLocked(ctx, book))
+	}
+	return created, nil
+}
+
+// 책 정보 전체 수정
+func (m *memoryBookRepo) Update(ctx context.Context, id string, book Book) (Book, error) {
+	return m.Patch(ctx, id, bookPatch{Title: &book.Title, Author: &book.Author, Year: &book.Year, ISBN: &book.ISBN, Version: &book.Version})
+}
+
+// 보낸 필드만 수정 (수정할 때마다 버전 증가)
+func (m *memoryBookRepo) Patch(ctx context.Context, id string, patch bookPatch) (Book, error) {
+	m = m.writeTarget(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.lookupLocked(ctx, id, false)
+	if !ok {
+		return Book{}, errBookNotFound
+	}
+	if patch.Version != nil && *patch.Version != record.book.Version {
+		return Book{}, &versionConflictError{Current: record.book.Version}
+	}
+	if patch.Title == nil && patch.Author == nil && patch.Year == nil && patch.ISBN == nil {
+		return record.book, nil
+	}
+	if patch.ISBN != nil && m.isbnTakenLocked(*patch.ISBN, id) {
+		return Book{}, &duplicateKeyError{Field: "isbn"}
+	}
+	if patch.Title != nil {
+		record.book.Title = *patch.Title
+	}
+	if patch.Author != nil {
+		record.book.Author = *patch.Author
+	}
+	if patch.Year != nil {
+		record.book.Year = *patch.Year
+	}
+	if patch.ISBN != nil {
+		record.book.ISBN = *patch.ISBN
+	}
+	oldBook := m.items[id].book
+	record.book.UpdatedAt = formatTimestamp(time.Now())
+	record.book.Version++
+	m.items[id] = record
+	m.auditLocked(ctx, auditUpdate, &oldBook, &record.book)
+	return record.book, nil
+}
+
+// 책 삭제 (SOFT_DELETE 사용 시 삭제 표시만)
+func (m *memoryBookRepo) Delete(ctx context.Context, id string, expected *int) error {
+	m = m.writeTarget(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.lookupLocked(ctx, id, false)
+	if !ok {
+		return errBookNotFound
+	}
+	if expected != nil && *expected != record.book.Version {
+		return &versionConflictError{Current: record.book.Version}
+	}
+	m.auditLocked(ctx, auditDelete, &record.book, nil)
+	if softDeleteEnabled() {
+		record.deleted = true
+		m.items[id] = record
+		return nil
+	}
+	delete(m.items, id)
+	return nil
+}
+
+// 여러 책 삭제 (잠금 안에서 한 번에 처리)
+func (m *memoryBookRepo) DeleteMany(ctx context.Context, ids []string) ([]string, error) {
+	m = m.writeTarget(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted []string
+	for _, id := range ids {
+		record, ok := m.lookupLocked(ctx, id, false)
+		if !ok {
+			continue
+		}
+		m.auditLocked(ctx, auditDelete, &record.book, nil)
+		if softDeleteEnabled() {
+			record.deleted = true
+			m.items[id] = record
+		} else {
+			delete(m.items, id)
+		}
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+// 책 변경 이력 조회 (기록 순서)
+func (m *memoryBookRepo) History(ctx context.Context, id string) ([]auditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenant := tenantFromContext(ctx)
+	result := []auditEntry{}
+	for _, record := range m.audit {
+		if record.entry.BookID == id && record.tenant == tenant {
+			result = append(result, record.entry)
+		}
+	}
+	return result, nil
+}
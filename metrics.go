@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "처리한 HTTP 요청 수",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP 요청 처리 시간",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "books_db_query_duration_seconds",
+		Help:    "책 저장소 DB 호출 시간",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "books_db_open_connections",
+		Help: "현재 열려 있는 DB 연결 수 (사용 중 + 유휴)",
+	}, func() float64 {
+		if db == nil {
+			return 0
+		}
+		return float64(db.Stats().OpenConnections)
+	})
+)
+
+// 요청 메트릭 미들웨어 (router.Use로 등록)
+// path 라벨은 실제 URL이 아닌 라우트 템플릿(/books/{id})을 사용해 라벨 수가 늘어나지 않게 한다.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := "unknown"
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil {
+				path = tmpl
+			}
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequestsTotal.WithLabelValues(r.Method, path, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
+	})
+}
+
+// DB 호출 시간 기록 (defer observeDBQuery("list", time.Now()) 형태로 사용)
+func observeDBQuery(operation string, start time.Time) {
+	dbQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// 폐기 예정 라우트 안내 미들웨어 (RFC 8594 Deprecation/Sunset 헤더)
+//
+// 적용 대상: /books, /books/schema, /books/{id}, /books/import.ndjson 등
+// 접두사 없는 /books 하위의 모든 레거시 라우트. /v1 라우트와 /health는 제외한다.
+// DEPRECATION_DATE, SUNSET_DATE가 모두 비어 있으면 헤더를 추가하지 않는다.
+func deprecationMiddleware(deprecation, sunset time.Time) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if deprecation.IsZero() && sunset.IsZero() {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !deprecation.IsZero() {
+				w.Header().Set("Deprecation", deprecation.UTC().Format(http.TimeFormat))
+			}
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// 디버그용 핸들러 이름 헤더 미들웨어 (라우트 등록 시 지정한 이름을 X-Handler로 응답)
+func debugHandlerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() != "" {
+			w.Header().Set("X-Handler", route.GetName())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 정규 호스트 리다이렉트 미들웨어 (CANONICAL_HOST, 비어 있으면 사용 안 함)
+//
+// Host 헤더가 정규 호스트와 다르면 경로와 쿼리를 유지한 채 301로 리다이렉트한다.
+// 프록시 뒤에서 Host가 내부 이름으로 바뀌는 경우 useForwarded(CANONICAL_HOST_USE_FORWARDED)를 켜면
+// X-Forwarded-Host / X-Forwarded-Proto 값을 기준으로 판단한다. 이 헤더는 클라이언트가 임의로 보낼 수 있으므로
+// 프록시가 항상 덮어쓰는 환경에서만 켜야 한다.
+// 로드밸런서나 모니터링이 IP로 호출하는 /health, /ready, /healthz/detail, /version, /metrics는 리다이렉트하지 않는다 (BASE_PATH를 뗀 경로 기준).
+func canonicalHostMiddleware(canonicalHost string, useForwarded bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if canonicalHost == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			if useForwarded {
+				if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+					host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+				}
+				if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+					scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+				}
+			}
+
+			if r.URL.Path == "/health" || r.URL.Path == "/ready" || r.URL.Path == "/healthz/detail" || r.URL.Path == "/version" || r.URL.Path == "/metrics" || strings.EqualFold(host, canonicalHost) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// BASE_PATH를 뗀 r.URL이 아닌 클라이언트가 보낸 원래 경로 사용
+			target := scheme + "://" + canonicalHost + r.RequestURI
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		})
+	}
+}
+
+// CORS 허용 메서드 / 요청 헤더 / 브라우저에 노출할 응답 헤더
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Content-Type, Accept, Authorization, X-API-Key, X-Feature-Flags, If-Match, If-None-Match, Idempotency-Key, Dry-Run"
+	corsExposeHeaders = "Location, Link, X-Total-Count, X-Request-ID, Retry-After, ETag, Idempotent-Replayed"
+)
+
+// CORS 미들웨어 (ALLOWED_ORIGINS, 비어 있으면 사용 안 함)
+//
+// 허용 목록에 있는 Origin이면 그대로 Access-Control-Allow-Origin으로 돌려주고,
+// "*"가 있으면 모든 Origin을 허용한다. 인증은 쿠키가 아닌 X-API-Key/Authorization 헤더를 사용하므로
+// Access-Control-Allow-Credentials는 보내지 않는다 ("*"와 함께 쓸 수 없음).
+// 프리플라이트(OPTIONS + Access-Control-Request-Method)는 인증 없이 204로 응답한다.
+func corsMiddleware(allowedOrigins map[string]struct{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(allowedOrigins) == 0 {
+			return next
+		}
+		_, allowAny := allowedOrigins["*"]
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			_, allowed := allowedOrigins[strings.ToLower(origin)]
+			if !allowAny && !allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAny {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 조회 응답 형식
+const (
+	formatJSON = "json"
+	formatXML  = "xml"
+)
+
+// Accept 미디어 타입별 응답 형식 (application/problem+json은 에러 형식 지정이므로 JSON으로 취급)
+var acceptFormats = map[string]string{
+	"*/*":                      formatJSON,
+	"application/*":            formatJSON,
+	"application/json":         formatJSON,
+	"application/problem+json": formatJSON,
+	"application/xml":          formatXML,
+	"text/*":                   formatXML,
+	"text/xml":                 formatXML,
+}
+
+// Accept 헤더로 조회 응답 형식 결정
+//
+// Accept가 없으면 JSON이다. 여러 타입이 있으면 q 값이 큰 것, 같으면 와일드카드보다 구체적인 타입을 고른다.
+// 지원하는 형식(application/json, application/xml, text/xml)이 하나도 없으면 406 응답 후 false를 반환한다.
+func negotiateFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	w.Header().Add("Vary", "Accept")
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, true
+	}
+
+	best, bestQ, bestRank := "", 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, ok := acceptFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		rank := 2 - strings.Count(mediaType, "*")
+		if q > bestQ || (q == bestQ && rank > bestRank) {
+			best, bestQ, bestRank = format, q, rank
+		}
+	}
+
+	if best == "" {
+		writeError(w, r, http.StatusNotAcceptable, "지원하지 않는 응답 형식입니다 (가능: application/json, application/xml)", nil)
+		return "", false
+	}
+	return best, true
+}
+
+// 조회 응답 본문 작성
+// XML이면 root 요소로 감싸고, 슬라이스는 항목마다 item 요소로 기록한다.
+func writeFormatted(w http.ResponseWriter, format string, v interface{}, root, item string) {
+	if format != formatXML {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	start := xml.StartElement{Name: xml.Name{Local: root}}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		enc.EncodeToken(start)
+		for i := 0; i < rv.Len(); i++ {
+			enc.EncodeElement(rv.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: item}})
+		}
+		enc.EncodeToken(start.End())
+	} else {
+		enc.EncodeElement(v, start)
+	}
+	enc.Flush()
+	w.Write([]byte("\n"))
+}
+
+// name 요소 하나로 XML 인코딩 (스트리밍 응답에서 항목별로 사용)
+func marshalXMLElement(v interface{}, name string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 할당량 카운터 테이블 (시작 시 책 테이블과 같은 DB_CATALOG/DB_SCHEMA로 설정)
+// 필요한 테이블:
+//
+//	CREATE TABLE bz.dbo.tbl_api_quota (
+//		key_hash     char(64) NOT NULL,
+//		window_start date     NOT NULL,
+//		count        int      NOT NULL,
+//		PRIMARY KEY (key_hash, window_start)
+//	)
+var quotaTable = "bz.dbo.tbl_api_quota"
+
+// 클라이언트(API 키, JWT sub)별 일일 쓰기 할당량 미들웨어
+//
+// 카운터는 재시작 후에도 유지되도록 quotaTable에 저장한다 (UTC 기준 일 단위 윈도우).
+// 요청 전에 카운터를 올려 한도를 예약하고, 쓰기가 성공(2xx)하지 않으면 되돌린다.
+// 검증 실패나 충돌, DB 에러로 끝난 요청과 한도 초과로 거부된 요청(429)은 할당량을 쓰지 않는다.
+// limit이 0이면 할당량을 적용하지 않는다. 카운터 갱신에 실패하면 요청은 허용한다.
+// 드라이런 요청은 아무것도 반영하지 않으므로 할당량을 쓰지 않는다 (dryRunMiddleware 뒤에 적용).
+func quotaMiddleware(limit int) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if limit <= 0 {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			if isDryRun(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now().UTC()
+			windowStart := now.Truncate(24 * time.Hour)
+			reset := windowStart.Add(24 * time.Hour)
+			keyHash := hashAPIKey(clientID(r))
+
+			ctx, cancel := dbContext(r.Context())
+			count, err := quotas.increment(ctx, keyHash, windowStart)
+			cancel()
+			if err != nil {
+				requestLogger(r.Context()).Error("할당량 카운터 갱신 에러", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			remaining := limit - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			w.Header().Set("X-Quota-Limit", strconv.Itoa(limit))
+			w.Header().Set("X-Quota-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-Quota-Reset", reset.Format(time.RFC3339))
+
+			if count > limit {
+				refundQuota(r, keyHash, windowStart)
+				w.Header().Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+				writeError(w, r, http.StatusTooManyRequests, "일일 쓰기 할당량을 초과했습니다", map[string]interface{}{
+					"reset": reset.Format(time.RFC3339),
+				})
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status != 0 && (rec.status < 200 || rec.status >= 300) {
+				refundQuota(r, keyHash, windowStart)
+			}
+		}
+	}
+}
+
+// 예약한 할당량 되돌리기 (요청이 취소되거나 시간 초과여도 되돌리도록 요청 컨텍스트의 취소는 따르지 않음)
+func refundQuota(r *http.Request, keyHash string, windowStart time.Time) {
+	ctx, cancel := dbContext(context.WithoutCancel(r.Context()))
+	defer cancel()
+	if err := quotas.refund(ctx, keyHash, windowStart); err != nil {
+		requestLogger(r.Context()).Error("할당량 카운터 되돌리기 에러", "error", err)
+	}
+}
+
+// 할당량 카운터 저장소 (기본은 quotaTable)
+type quotaStore interface {
+	// 카운터 증가 후 현재 윈도우의 누적 횟수 반환
+	increment(ctx context.Context, keyHash string, windowStart time.Time) (int, error)
+	// 카운터 1 감소 (0 미만으로는 내려가지 않음)
+	refund(ctx context.Context, keyHash string, windowStart time.Time) error
+}
+
+var quotas quotaStore = mssqlQuotaStore{}
+
+// MSSQL 할당량 카운터 저장소
+type mssqlQuotaStore struct{}
+
+func (mssqlQuotaStore) increment(ctx context.Context, keyHash string, windowStart time.Time) (int, error) {
+	query := `MERGE ` + quotaTable + ` WITH (HOLDLOCK) AS t
+USING (SELECT ? AS key_hash, ? AS window_start) AS s
+ON t.key_hash = s.key_hash AND t.window_start = s.window_start
+WHEN MATCHED THEN UPDATE SET count = t.count + 1
+WHEN NOT MATCHED THEN INSERT (key_hash, window_start, count) VALUES (s.key_hash, s.window_start, 1)
+OUTPUT inserted.count;`
+
+	var count int
+	err := db.QueryRowContext(ctx, query, keyHash, windowStart).Scan(&count)
+	return count, err
+}
+
+func (mssqlQuotaStore) refund(ctx context.Context, keyHash string, windowStart time.Time) error {
+	_, err := db.ExecContext(ctx, "UPDATE "+quotaTable+" SET count = count - 1 WHERE key_hash = ? AND window_start = ? AND count > 0", keyHash, windowStart)
+	return err
+}
+
+// API 키 해시 (원본 키를 DB에 저장하지 않기 위함)
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 테스트용 메모리 할당량 카운터
+type memoryQuotaStore struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *memoryQuotaStore) increment(ctx context.Context, keyHash string, windowStart time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[keyHash]++
+	return s.counts[keyHash], nil
+}
+
+func (s *memoryQuotaStore) refund(ctx context.Context, keyHash string, windowStart time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts[keyHash] > 0 {
+		s.counts[keyHash]--
+	}
+	return nil
+}
+
+// 테스트 동안 메모리 카운터 사용
+func useMemoryQuotas(t *testing.T) *memoryQuotaStore {
+	t.Helper()
+	store := &memoryQuotaStore{counts: map[string]int{}}
+	previous := quotas
+	quotas = store
+	t.Cleanup(func() { quotas = previous })
+	return store
+}
+
+// ?status=로 받은 상태 코드로 응답하는 쓰기 핸들러
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+	w.WriteHeader(status)
+}
+
+func serveQuota(h http.HandlerFunc, status int) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/v1/books?status="+strconv.Itoa(status), nil)
+	req = req.WithContext(context.WithValue(req.Context(), clientIDKey, testAPIKey))
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestQuotaCountsOnlySuccessfulWrites(t *testing.T) {
+	appConfig = &Config{}
+	store := useMemoryQuotas(t)
+	h := quotaMiddleware(2)(statusHandler)
+
+	// 실패한 쓰기는 할당량을 쓰지 않음
+	for _, status := range []int{400, 404, 409, 412, 422, 500} {
+		if rec := serveQuota(h, status); rec.Code != status {
+			t.Fatalf("상태 코드 = %d, 원하는 값 %d", rec.Code, status)
+		}
+	}
+	if got := store.counts[hashAPIKey(testAPIKey)]; got != 0 {
+		t.Fatalf("실패한 쓰기 후 카운터 = %d, 원하는 값 0", got)
+	}
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := serveQuota(h, http.StatusCreated)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%d번째 쓰기 상태 코드 = %d, 원하는 값 201", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("X-Quota-Limit"); got != "2" {
+			t.Errorf("X-Quota-Limit = %q, 원하는 값 2", got)
+		}
+		if got := rec.Header().Get("X-Quota-Remaining"); got != wantRemaining {
+			t.Errorf("%d번째 쓰기 X-Quota-Remaining = %q, 원하는 값 %q", i+1, got, wantRemaining)
+		}
+	}
+
+	// 한도 초과 응답과 헤더 (거부된 요청도 할당량을 쓰지 않음)
+	for i := 0; i < 3; i++ {
+		rec := serveQuota(h, http.StatusCreated)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("한도 초과 상태 코드 = %d, 원하는 값 429", rec.Code)
+		}
+		reset := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		if got := rec.Header().Get("X-Quota-Reset"); got != reset.Format(time.RFC3339) {
+			t.Errorf("X-Quota-Reset = %q, 원하는 값 %q", got, reset.Format(time.RFC3339))
+		}
+		if got := rec.Header().Get("X-Quota-Remaining"); got != "0" {
+			t.Errorf("X-Quota-Remaining = %q, 원하는 값 0", got)
+		}
+		retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+		if err != nil || retryAfter < 1 || retryAfter > 24*60*60+1 {
+			t.Errorf("Retry-After = %q", rec.Header().Get("Retry-After"))
+		}
+		var body struct {
+			Reset string `json:"reset"`
+		}
+		decodeResponse(t, rec, &body)
+		if body.Reset != reset.Format(time.RFC3339) {
+			t.Errorf("reset = %q, 원하는 값 %q", body.Reset, reset.Format(time.RFC3339))
+		}
+	}
+	if got := store.counts[hashAPIKey(testAPIKey)]; got != 2 {
+		t.Errorf("카운터 = %d, 원하는 값 2", got)
+	}
+}
+
+// 드라이런 요청은 할당량 카운터를 건드리지 않아야 함
+func TestQuotaSkipsDryRun(t *testing.T) {
+	store := useMemoryQuotas(t)
+	called := false
+	h := chain(dryRunMiddleware, quotaMiddleware(1))(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/books?dry_run=true", nil))
+	if !called || rec.Code != http.StatusOK {
+		t.Errorf("드라이런 상태 코드 = %d, 핸들러 호출 = %v", rec.Code, called)
+	}
+	if rec.Header().Get("X-Quota-Remaining") != "" {
+		t.Error("드라이런 응답에 할당량 헤더가 있습니다")
+	}
+	if len(store.counts) != 0 {
+		t.Errorf("드라이런 후 카운터 = %v", store.counts)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+)
+
+// 이 시간 동안 요청이 없던 API 키의 리미터는 제거
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// API 키별 리미터
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// 라우트별 요청 속도 한도 (RATE_LIMIT_ROUTES, RPS가 0이면 그 라우트는 제한하지 않음)
+type routeRateLimit struct {
+	RPS   int
+	Burst int
+}
+
+// RATE_LIMIT_ROUTES 파싱 ("라우트이름=초당요청수[:버스트],..." 형식, 버스트를 생략하면 초당 요청 수와 같음)
+func parseRouteRateLimits(value string) (map[string]routeRateLimit, error) {
+	limits := map[string]routeRateLimit{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		rpsRaw, burstRaw, hasBurst := strings.Cut(strings.TrimSpace(spec), ":")
+		rps, err := strconv.Atoi(strings.TrimSpace(rpsRaw))
+		if !ok || name == "" || err != nil || rps < 0 {
+			return nil, fmt.Errorf("잘못된 RATE_LIMIT_ROUTES 항목입니다 (라우트이름=초당요청수[:버스트] 형식이어야 함): %q", pair)
+		}
+		limit := routeRateLimit{RPS: rps, Burst: rps}
+		if hasBurst {
+			burst, err := strconv.Atoi(strings.TrimSpace(burstRaw))
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("RATE_LIMIT_ROUTES의 버스트는 1 이상이어야 합니다: %q", pair)
+			}
+			limit.Burst = burst
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// 클라이언트(API 키, JWT sub)별 초당 요청 수 제한 미들웨어 (토큰 버킷)
+//
+// 인증을 통과한 키만 리미터를 만들도록 authMiddleware 안쪽에 둔다.
+// 한도를 넘으면 다음 토큰이 생길 때까지의 시간을 Retry-After로 알려주고 429로 응답한다.
+// routes에 있는 라우트(이름 기준)는 (라우트, 클라이언트)별 리미터로 그 라우트의 한도만 적용하고,
+// 나머지 라우트는 클라이언트별 리미터 하나로 rps/burst 한도를 함께 쓴다.
+// rps가 0이면 routes에 없는 라우트는 제한하지 않는다.
+func rateLimitMiddleware(rps, burst int, routes map[string]routeRateLimit) func(http.HandlerFunc) http.HandlerFunc {
+	if rps <= 0 && len(routes) == 0 {
+		return func(next http.HandlerFunc) http.HandlerFunc { return next }
+	}
+
+	// 리미터는 미들웨어 인스턴스 하나에 하나이므로 이 미들웨어로 감싼 모든 라우트(/v1, 레거시 경로)가 키별 한도를 공유한다
+	// (/v1과 레거시 경로는 라우트 이름이 같으므로 라우트별 한도도 함께 쓴다)
+	var mu sync.Mutex
+	limiters := map[string]*keyLimiter{}
+
+	// 오래 쓰이지 않은 리미터 정리 (맵이 무한히 커지지 않도록)
+	go func() {
+		for range time.Tick(time.Minute) {
+			cutoff := time.Now().Add(-rateLimiterIdleTTL)
+			mu.Lock()
+			for key, l := range limiters {
+				if l.lastSeen.Before(cutoff) {
+					delete(limiters, key)
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key, limit := clientID(r), routeRateLimit{RPS: rps, Burst: burst}
+			if route := mux.CurrentRoute(r); route != nil {
+				if routeLimit, ok := routes[route.GetName()]; ok {
+					key, limit = route.GetName()+" "+key, routeLimit
+				}
+			}
+			if limit.RPS <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mu.Lock()
+			l, ok := limiters[key]
+			if !ok {
+				l = &keyLimiter{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+				limiters[key] = l
+			}
+			l.lastSeen = time.Now()
+			mu.Unlock()
+
+			reservation := l.limiter.Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				reservation.Cancel()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				writeError(w, r, http.StatusTooManyRequests, "요청이 너무 많습니다. 잠시 후 다시 시도하세요", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseRouteRateLimits(t *testing.T) {
+	got, err := parseRouteRateLimits(" GetBooks=100 , CreateBook=5:10,DeleteBook=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]routeRateLimit{
+		"GetBooks":   {RPS: 100, Burst: 100},
+		"CreateBook": {RPS: 5, Burst: 10},
+		"DeleteBook": {RPS: 0, Burst: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRouteRateLimits = %v, 원하는 값 %v", got, want)
+	}
+
+	for _, value := range []string{"GetBooks", "=5", "GetBooks=-1", "GetBooks=x", "GetBooks=5:0", "GetBooks=5:x"} {
+		if _, err := parseRouteRateLimits(value); err == nil {
+			t.Errorf("parseRouteRateLimits(%q)가 에러를 반환하지 않았습니다", value)
+		}
+	}
+}
+
+func TestRouteRateLimits(t *testing.T) {
+	h := newTestHandler(t, map[string]string{
+		"RATE_LIMIT_RPS":    "1",
+		"RATE_LIMIT_BURST":  "2",
+		"RATE_LIMIT_ROUTES": "CreateBook=1,GetBooksCount=0",
+	})
+	book := `{"title":"토지","author":"박경리","year":1969}`
+
+	// 책 추가는 라우트별 한도 (/v1과 레거시 경로가 함께 씀)
+	if rec := doRequest(t, h, "POST", "/v1/books", book, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("첫 책 추가 상태 코드 = %d (%s)", rec.Code, rec.Body.String())
+	}
+	rec := doRequest(t, h, "POST", "/books", book, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("두 번째 책 추가 상태 코드 = %d, Retry-After = %q, 원하는 값 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// 다른 라우트는 책 추가 한도와 별개로 전체 한도(버스트 2)를 씀
+	for i := 0; i < 2; i++ {
+		if rec := doRequest(t, h, "GET", "/v1/books", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("%d번째 목록 조회 상태 코드 = %d, 원하는 값 200", i+1, rec.Code)
+		}
+	}
+	if rec := doRequest(t, h, "GET", "/v1/books/1", "", nil); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("전체 한도 초과 상태 코드 = %d, 원하는 값 429", rec.Code)
+	}
+
+	// 한도 0인 라우트는 제한하지 않음
+	for i := 0; i < 5; i++ {
+		if rec := doRequest(t, h, "GET", "/v1/books/count", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("건수 조회 상태 코드 = %d, 원하는 값 200", rec.Code)
+		}
+	}
+}
+
+// 라우트별 한도는 클라이언트마다 따로 적용
+func TestRouteRateLimitsPerClient(t *testing.T) {
+	book := `{"title":"토지","author":"박경리","year":1969}`
+	other := http.Header{"X-Api-Key": {"other-key-0123456789"}}
+	h := newTestHandler(t, map[string]string{
+		"API_KEY":           testAPIKey + ",other-key-0123456789",
+		"RATE_LIMIT_ROUTES": "CreateBook=1",
+	})
+	doRequest(t, h, "POST", "/v1/books", book, nil)
+	if rec := doRequest(t, h, "POST", "/v1/books", book, other); rec.Code != http.StatusCreated {
+		t.Errorf("다른 클라이언트 책 추가 상태 코드 = %d, 원하는 값 201", rec.Code)
+	}
+	// RATE_LIMIT_RPS 없이 라우트별 한도만 지정하면 다른 라우트는 제한하지 않음
+	for i := 0; i < 5; i++ {
+		if rec := doRequest(t, h, "GET", "/v1/books", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("목록 조회 상태 코드 = %d, 원하는 값 200", rec.Code)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 조건에 맞는 책이 없음 (조회/수정/삭제 대상 없음)
+var errBookNotFound = errors.New("책을 찾을 수 없습니다")
+
+// 고유 인덱스 위반 (Field는 충돌한 컬럼, 알 수 없으면 빈 문자열)
+type duplicateKeyError struct {
+	Field string
+}
+
+func (e *duplicateKeyError) Error() string {
+	switch e.Field {
+	case "":
+		return "이미 있는 값과 중복됩니다"
+	case "isbn":
+		return "ISBN already exists"
+	}
+	return e.Field + " already exists"
+}
+
+// 고유 인덱스가 있는 컬럼 (제약/인덱스 이름에 컬럼 이름이 들어 있어야 충돌 필드를 알 수 있음)
+var uniqueColumns = []string{"isbn"}
+
+// MSSQL 에러 메시지의 제약/인덱스 이름 ("... constraint 'UQ_x'", "... unique index 'ux_book_isbn'")
+var uniqueIndexNamePattern = regexp.MustCompile(`(?i)(?:constraint|index) '([^']+)'`)
+
+// 고유 제약 위반이면 *duplicateKeyError로 변환 (그 밖의 에러는 그대로)
+func translateUniqueViolation(err error) error {
+	if !isUniqueViolation(err) {
+		return err
+	}
+	dup := &duplicateKeyError{}
+	if m := uniqueIndexNamePattern.FindStringSubmatch(err.Error()); m != nil {
+		name := strings.ToLower(m[1])
+		for _, column := range uniqueColumns {
+			if strings.Contains(name, column) {
+				dup.Field = column
+				break
+			}
+		}
+	}
+	return dup
+}
+
+// 수정 요청의 버전이 현재 버전과 다름 (그 사이 다른 요청이 수정함)
+type versionConflictError struct {
+	Current int
+}
+
+func (e *versionConflictError) Error() string {
+	return fmt.Sprintf("버전이 일치하지 않습니다 (현재 버전 %d)", e.Current)
+}
+
+// 책 저장소 (핸들러는 DB 대신 이 인터페이스에 의존)
+// 멀티 테넌시 사용 시 테넌트는 ctx(tenantKey) 또는 bookFilter.Tenant로 전달한다.
+type BookRepository interface {
+	List(ctx context.Context, filter bookFilter, sort bookSort, page pagination) ([]Book, error)
+	// 결과를 한 건씩 fn에 전달 (fn이 에러를 반환하면 중단)
+	Each(ctx context.Context, filter bookFilter, sort bookSort, page pagination, fn func(Book) error) error
+	Count(ctx context.Context, filter bookFilter) (int, error)
+	CountByYear(ctx context.Context, filter bookFilter) ([]yearCount, error)
+	Get(ctx context.Context, id string, includeDeleted bool) (Book, error)
+	Create(ctx context.Context, book Book) (Book, error)
+	// 추가/수정 시 고유 인덱스 위반은 *duplicateKeyError로 반환
+	// 하나의 트랜잭션으로 추가 (실패 시 *batchRowError로 실패 위치 반환)
+	CreateBatch(ctx context.Context, batch []Book) ([]Book, error)
+	// 수정/삭제는 SOFT_DELETE 사용 시 이미 삭제된 책을 찾을 수 없는 것으로 취급
+	// 수정은 book.Version(patch.Version), 삭제는 expected가 현재 버전과 다르면 *versionConflictError 반환
+	Update(ctx context.Context, id string, book Book) (Book, error)
+	Patch(ctx context.Context, id string, patch bookPatch) (Book, error)
+	Delete(ctx context.Context, id string, expected *int) error
+	// 여러 책을 하나의 트랜잭션으로 삭제하고 실제로 삭제된 ID 반환
+	DeleteMany(ctx context.Context, ids []string) ([]string, error)
+	// 추가/수정/삭제는 같은 트랜잭션에서 변경 이력을 남기며, History는 그 이력을 오래된 순으로 반환 (없으면 빈 슬라이스)
+	History(ctx context.Context, id string) ([]auditEntry, error)
+}
+
+// 출판 연도별 책 수
+type yearCount struct {
+	Year  int `json:"year" xml:"year"`
+	Count int `json:"count" xml:"count"`
+}
+
+// 부분 수정 필드 (nil이면 수정하지 않음)
+type bookPatch struct {
+	Title  *string
+	Author *string
+	Year   *int
+	ISBN   *string
+	// 클라이언트가 알고 있는 버전 (nil이면 버전 확인 없이 수정)
+	Version *int
+}
+
+// 책 저장소 (시작 시 MSSQL 구현으로 설정)
+var repo BookRepository
+
+// 소프트 삭제 사용 여부
+func softDeleteEnabled() bool {
+	return appConfig != nil && appConfig.SoftDelete
+}
+
+// 삭제되지 않은 행만 대상으로 하는 조건 (SOFT_DELETE 미사용 시 빈 문자열)
+func notDeletedCondition() string {
+	if !softDeleteEnabled() {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
+
+// MSSQL 책 저장소
+// 읽기는 withReadConn, 여러 행 쓰기는 withWriteTx를 거치므로 두 함수가 쓰는 패키지 DB 풀과 같은 풀이어야 한다.
+type mssqlBookRepo struct {
+	db *sql.DB
+}
+
+// MSSQL 책 저장소 생성
+func newMSSQLBookRepo(db *sql.DB) *mssqlBookRepo {
+	return &mssqlBookRepo{db: db}
+}
+
+// 책 목록 조회 (필터, 정렬, 페이지 범위 적용)
+func (s *mssqlBookRepo) List(ctx context.Context, filter bookFilter, sort bookSort, page pagination) ([]Book, error) {
+	result := []Book{}
+	err := s.Each(ctx, filter, sort, page, func(book Book) error {
+		result = append(result, book)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 책 목록을 커서에서 한 건씩 전달 (결과 전체를 메모리에 올리지 않음)
+func (s *mssqlBookRepo) Each(ctx context.Context, filter bookFilter, sort bookSort, page pagination, fn func(Book) error) error {
+	defer observeDBQuery("list", time.Now())
+
+	columns := selectableFields
+	if len(filter.Fields) > 0 {
+		columns = filter.Fields
+	}
+	where, args := page.where(filter.where())
+	paging, pagingArgs := page.clause()
+	args = append(args, pagingArgs...)
+	return withReadConn(ctx, func(q readQuerier) error {
+		rows, err := q.QueryContext(ctx, "SELECT "+strings.Join(columns, ", ")+" FROM "+bookTable+where+sort.orderBy()+paging, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			book, err := scanBookColumns(rows, columns)
+			if err != nil {
+				return err
+			}
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// 필터 조건에 맞는 책 수 조회
+func (s *mssqlBookRepo) Count(ctx context.Context, filter bookFilter) (int, error) {
+	defer observeDBQuery("count", time.Now())
+
+	where, args := filter.where()
+	var count int
+	err := withReadConn(ctx, func(q readQuerier) error {
+		return q.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+bookTable+where, args...).Scan(&count)
+	})
+	return count, err
+}
+
+// 출판 연도별 책 수 (연도 오름차순)
+func (s *mssqlBookRepo) CountByYear(ctx context.Context, filter bookFilter) ([]yearCount, error) {
+	defer observeDBQuery("count_by_year", time.Now())
+
+	where, args := filter.where()
+	result := []yearCount{}
+	err := withReadConn(ctx, func(q readQuerier) error {
+		rows, err := q.QueryContext(ctx, "SELECT year, COUNT(*) FROM "+bookTable+where+" GROUP BY year ORDER BY year", args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var yc yearCount
+			if err := rows.Scan(&yc.Year, &yc.Count); err != nil {
+				return err
+			}
+			result = append(result, yc)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 특정 ID의 책 조회
+func (s *mssqlBookRepo) Get(ctx context.Context, id string, includeDeleted bool) (Book, error) {
+	defer observeDBQuery("get", time.Now())
+
+	tenantWhere, tenantArgs := tenantCondition(ctx)
+	if !includeDeleted {
+		tenantWhere += notDeletedCondition()
+	}
+	var book Book
+	err := withReadConn(ctx, func(q readQuerier) error {
+		var err error
+		book, err = scanBook(q.QueryRowContext(ctx,
+			"SELECT "+bookColumns+" FROM "+bookTable+" WHERE id = ?"+tenantWhere, append([]interface{}{id}, tenantArgs...)...))
+		return err
+	})
+	if err == sql.ErrNoRows {
+		return Book{}, errBookNotFound
+	}
+	return book, err
+}
+
+// 책 추가 (쓰기 큐 사용 시 다른 요청과 모아서 배치 INSERT, 드라이런은 큐를 거치지 않음)
+func (s *mssqlBookRepo) Create(ctx context.Context, book Book) (Book, error) {
+	defer observeDBQuery("create", time.Now())
+
+	if insertQueue != nil && !isDryRun(ctx) {
+		return enqueueInsert(ctx, book)
+	}
+
+	// OUTPUT으로 방금 추가한 행을 그대로 반환받고 같은 트랜잭션에서 변경 이력 기록
+	tenantColumn, tenantPlaceholder, tenantArgs := tenantInsert(ctx)
+	query := "INSERT INTO " + bookTable + " (title, author, year, isbn, regdate, updated_at, version" + tenantColumn + ") " +
+		"OUTPUT " + insertedBookColumns + " " +
+		"VALUES (?, ?, ?, ?, GETUTCDATE(), GETUTCDATE(), 1" + tenantPlaceholder + ")"
+	args := append([]interface{}{book.Title, book.Author, book.Year, nullableISBN(book.ISBN)}, tenantArgs...)
+	var newBook Book
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if newBook, err = scanBook(tx.QueryRowContext(ctx, query, args...)); err != nil {
+			return err
+		}
+		return insertAudit(ctx, tx, auditSourceFromContext(ctx), auditCreate, nil, &newBook)
+	})
+	if err != nil {
+		return Book{}, translateUniqueViolation(err)
+	}
+	return newBook, nil
+}
+
+// 배치 중 특정 행의 INSERT 실패 (트랜잭션 전체가 롤백됨)
+type batchRowError struct {
+	Index int
+	Err   error
+}
+
+func (e *batchRowError) Error() string {
+	return fmt.Sprintf("%d번째 항목 저장 실패: %v", e.Index, e.Err)
+}
+
+func (e *batchRowError) Unwrap() error {
+	return e.Err
+}
+
+// 여러 책을 하나의 트랜잭션으로 추가하고 추가된 행을 반환
+func (s *mssqlBookRepo) CreateBatch(ctx context.Context, batch []Book) ([]Book, error) {
+	defer observeDBQuery("create_batch", time.Now())
+
+	tenantColumn, tenantPlaceholder, tenantArgs := tenantInsert(ctx)
+	query := "INSERT INTO " + bookTable + " (title, author, year, isbn, regdate, updated_at, version" + tenantColumn + ") " +
+		"OUTPUT " + insertedBookColumns + " " +
+		"VALUES (?, ?, ?, ?, GETUTCDATE(), GETUTCDATE(), 1" + tenantPlaceholder + ")"
+
+	source := auditSourceFromContext(ctx)
+	created := make([]Book, 0, len(batch))
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		for i, book := range batch {
+			args := append([]interface{}{book.Title, book.Author, book.Year, nullableISBN(book.ISBN)}, tenantArgs...)
+			newBook, err := scanBook(tx.QueryRowContext(ctx, query, args...))
+			if err != nil {
+				return &batchRowError{Index: i, Err: translateUniqueViolation(err)}
+			}
+			if err := insertAudit(ctx, tx, source, auditCreate, nil, &newBook); err != nil {
+				return err
+			}
+			created = append(created, newBook)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// 책 정보 전체 수정 후 수정된 행 반환
+func (s *mssqlBookRepo) Update(ctx context.Context, id string, book Book) (Book, error) {
+	columns := []string{"title = ?", "author = ?", "year = ?", "isbn = ?"}
+	return s.update(ctx, id, columns, []interface{}{book.Title, book.Author, book.Year, nullableISBN(book.ISBN)}, &book.Version)
+}
+
+// 보낸 필드만 수정 후 수정된 행 반환
+func (s *mssqlBookRepo) Patch(ctx context.Context, id string, patch bookPatch) (Book, error) {
+	var columns []string
+	var args []interface{}
+	if patch.Title != nil {
+		columns = append(columns, "title = ?")
+		args = append(args, *patch.Title)
+	}
+	if patch.Author != nil {
+		columns = append(columns, "author = ?")
+		args = append(args, *patch.Author)
+	}
+	if patch.Year != nil {
+		columns = append(columns, "year = ?")
+		args = append(args, *patch.Year)
+	}
+	if patch.ISBN != nil {
+		columns = append(columns, "isbn = ?")
+		args = append(args, nullableISBN(*patch.ISBN))
+	}
+	if len(columns) == 0 {
+		return s.Get(ctx, id, false)
+	}
+	return s.update(ctx, id, columns, args, patch.Version)
+}
+
+// UPDATE ... OUTPUT으로 수정과 수정 전/후 행 조회를 한 번에 처리하고 같은 트랜잭션에서 변경 이력 기록
+// expected가 있으면 버전이 같을 때만 수정하고, 수정되지 않았으면 다시 조회해 404와 버전 충돌을 구분한다.
+func (s *mssqlBookRepo) update(ctx context.Context, id string, columns []string, args []interface{}, expected *int) (Book, error) {
+	defer observeDBQuery("update", time.Now())
+
+	tenantWhere, tenantArgs := tenantCondition(ctx)
+	where := "WHERE id = ?" + tenantWhere + notDeletedCondition()
+	args = append(append(args, id), tenantArgs...)
+	if expected != nil {
+		where += " AND version = ?"
+		args = append(args, *expected)
+	}
+	query := "UPDATE " + bookTable + " SET " + strings.Join(append(columns, "updated_at = GETUTCDATE()", "version = version + 1"), ", ") + " " +
+		"OUTPUT " + deletedBookColumns + ", " + insertedBookColumns + " " + where
+	var book Book
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		oldBook, newBook, err := scanBookChange(tx.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+		book = newBook
+		return insertAudit(ctx, tx, auditSourceFromContext(ctx), auditUpdate, &oldBook, &newBook)
+	})
+	if err != sql.ErrNoRows {
+		return book, translateUniqueViolation(err)
+	}
+	if expected == nil {
+		return Book{}, errBookNotFound
+	}
+
+	current, err := s.Get(ctx, id, false)
+	if err != nil {
+		return Book{}, err
+	}
+	return Book{}, &versionConflictError{Current: current.Version}
+}
+
+// 책 삭제 (SOFT_DELETE 사용 시 deleted_at만 기록, 삭제 전 행을 변경 이력에 기록)
+// expected가 있으면 버전이 같을 때만 삭제하고, 삭제되지 않았으면 다시 조회해 404와 버전 충돌을 구분한다.
+func (s *mssqlBookRepo) Delete(ctx context.Context, id string, expected *int) error {
+	defer observeDBQuery("delete", time.Now())
+
+	tenantWhere, tenantArgs := tenantCondition(ctx)
+	where := " WHERE id = ?" + tenantWhere
+	args := append([]interface{}{id}, tenantArgs...)
+	if expected != nil {
+		where += " AND version = ?"
+		args = append(args, *expected)
+	}
+	query := "DELETE FROM " + bookTable + " OUTPUT " + deletedBookColumns + where
+	if softDeleteEnabled() {
+		query = "UPDATE " + bookTable + " SET deleted_at = GETUTCDATE() OUTPUT " + deletedBookColumns + where + notDeletedCondition()
+	}
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		oldBook, err := scanBook(tx.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+		return insertAudit(ctx, tx, auditSourceFromContext(ctx), auditDelete, &oldBook, nil)
+	})
+	if err != sql.ErrNoRows {
+		return err
+	}
+	if expected == nil {
+		return errBookNotFound
+	}
+
+	current, err := s.Get(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	return &versionConflictError{Current: current.Version}
+}
+
+// 여러 책을 한 문장으로 삭제 (IN 절, SOFT_DELETE 사용 시 deleted_at만 기록)
+func (s *mssqlBookRepo) DeleteMany(ctx context.Context, ids []string) ([]string, error) {
+	defer observeDBQuery("delete_many", time.Now())
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)+1)
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	tenantWhere, tenantArgs := tenantCondition(ctx)
+	args = append(args, tenantArgs...)
+
+	where := " WHERE id IN (" + strings.Join(placeholders, ", ") + ")" + tenantWhere
+	query := "DELETE FROM " + bookTable + " OUTPUT " + deletedBookColumns + where
+	if softDeleteEnabled() {
+		query = "UPDATE " + bookTable + " SET deleted_at = GETUTCDATE() OUTPUT " + deletedBookColumns + where + notDeletedCondition()
+	}
+
+	// 변경 이력은 결과를 끝까지 읽어 커서가 닫힌 뒤 같은 트랜잭션에서 기록
+	var deleted []string
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var oldBooks []Book
+		for rows.Next() {
+			book, err := scanBook(rows)
+			if err != nil {
+				return err
+			}
+			oldBooks = append(oldBooks, book)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		source := auditSourceFromContext(ctx)
+		for i := range oldBooks {
+			if err := insertAudit(ctx, tx, source, auditDelete, &oldBooks[i], nil); err != nil {
+				return err
+			}
+			deleted = append(deleted, oldBooks[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// 책 변경 이력 조회 (이력 id 오름차순)
+func (s *mssqlBookRepo) History(ctx context.Context, id string) ([]auditEntry, error) {
+	defer observeDBQuery("history", time.Now())
+
+	tenantWhere, tenantArgs := tenantCondition(ctx)
+	result := []auditEntry{}
+	err := withReadConn(ctx, func(q readQuerier) error {
+		rows, err := q.QueryContext(ctx,
+			"SELECT id, book_id, operation, old_values, new_values, actor, changed_at FROM "+auditTable+" WHERE book_id = ?"+tenantWhere+" ORDER BY id",
+			append([]interface{}{id}, tenantArgs...)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			entry, err := scanAuditEntry(rows)
+			if err != nil {
+				return err
+			}
+			result = append(result, entry)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// 라우트 미들웨어
+type routeMiddleware func(http.HandlerFunc) http.HandlerFunc
+
+// 책 API 라우트 등록
+// 버전별 서브라우터(/v1 등)와 접두사 없는 레거시 경로가 같은 등록 코드를 쓴다.
+// 새 버전은 서브라우터를 만들어 이 함수를 호출하고, 달라지는 핸들러만 따로 덮어쓴다.
+// read는 조회 라우트, write는 쓰기 라우트, create는 책 추가(POST /books)에 적용할 미들웨어다.
+func registerBookRoutes(r *mux.Router, read, write, create routeMiddleware) {
+	r.HandleFunc("/books", read(GetBooks)).Methods("GET").Name("GetBooks")
+	r.HandleFunc("/books/schema", read(GetBookSchema)).Methods("GET").Name("GetBookSchema")
+	r.HandleFunc("/books/count", read(GetBooksCount)).Methods("GET").Name("GetBooksCount")
+	r.HandleFunc("/books/stats/by-year", read(GetBookStatsByYear)).Methods("GET").Name("GetBookStatsByYear")
+	r.HandleFunc("/books/export.csv", read(ExportBooksCSV)).Methods("GET").Name("ExportBooksCSV")
+	r.HandleFunc("/books/{id}", read(GetBook)).Methods("GET").Name("GetBook")
+	r.HandleFunc("/books/{id}", read(GetBook)).Methods("HEAD").Name("HeadBook")
+	r.HandleFunc("/books/{id}/history", read(GetBookHistory)).Methods("GET").Name("GetBookHistory")
+	r.HandleFunc("/authors/{author}/books", read(GetAuthorBooks)).Methods("GET").Name("GetAuthorBooks")
+	r.HandleFunc("/books", create(CreateBook)).Methods("POST").Name("CreateBook")
+	r.HandleFunc("/books/batch", write(CreateBooksBatch)).Methods("POST").Name("CreateBooksBatch")
+	r.HandleFunc("/books/import.ndjson", write(ImportBooksNDJSON)).Methods("POST").Name("ImportBooksNDJSON")
+	r.HandleFunc("/books/import", write(ImportBooksCSV)).Methods("POST").Name("ImportBooksCSV")
+	r.HandleFunc("/books/bulk-delete", write(BulkDeleteBooks)).Methods("POST").Name("BulkDeleteBooks")
+	r.HandleFunc("/books/{id}", write(UpdateBook)).Methods("PUT").Name("UpdateBook")
+	r.HandleFunc("/books/{id}", write(PatchBook)).Methods("PATCH").Name("PatchBook")
+	r.HandleFunc("/books/{id}", write(DeleteBook)).Methods("DELETE").Name("DeleteBook")
+}
+
+// 미들웨어 합성 (앞에 있는 것이 바깥쪽)
+func chain(middlewares ...routeMiddleware) routeMiddleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
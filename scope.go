@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// JWT_ROUTE_SCOPES 파싱 ("라우트이름=스코프,라우트이름=스코프" 형식, 스코프가 비어 있으면 인증만 요구)
+func parseRouteScopes(value string) (map[string]string, error) {
+	scopes := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, scope, ok := strings.Cut(pair, "=")
+		name, scope = strings.TrimSpace(name), strings.TrimSpace(scope)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("잘못된 JWT_ROUTE_SCOPES 항목입니다 (라우트이름=스코프 형식이어야 함): %q", pair)
+		}
+		scopes[name] = scope
+	}
+	return scopes, nil
+}
+
+// JWT 스코프 확인 미들웨어 (auth 뒤에 적용)
+//
+// JWT로 인증한 요청은 토큰의 scope/role 클레임에 필요한 스코프가 있어야 하며, 없으면 403으로 응답한다.
+// 필요한 스코프는 라우트 이름(GetBooks, CreateBook 등)으로 overrides에서 찾고, 없으면 defaultScope를 쓴다.
+// 스코프가 빈 문자열이면 인증된 토큰이면 모두 허용한다. API 키로 인증한 요청은 확인하지 않는다.
+func scopeMiddleware(defaultScope string, overrides map[string]string) routeMiddleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := jwtClaimsFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			required := defaultScope
+			if route := mux.CurrentRoute(r); route != nil {
+				if scope, ok := overrides[route.GetName()]; ok {
+					required = scope
+				}
+			}
+			if required != "" && !claims.hasScope(required) {
+				writeError(w, r, http.StatusForbidden, "이 요청에 필요한 권한이 없습니다", map[string]interface{}{"required_scope": required})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// 토큰이 스코프를 가졌는지 여부
+// scope(공백 구분 문자열 또는 배열)와 role(문자열 또는 배열) 클레임을 모두 확인한다.
+func (c jwtClaims) hasScope(scope string) bool {
+	for _, name := range []string{"scope", "role"} {
+		switch v := c[name].(type) {
+		case string:
+			for _, s := range strings.Fields(v) {
+				if s == scope {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && s == scope {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
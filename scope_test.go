@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// 테스트용 HS256 비밀키 (JWT_SECRET 최소 길이 32바이트)
+const testJWTSecret = "test-jwt-secret-0123456789abcdef"
+
+// 테스트 비밀키로 HS256 토큰 서명
+func signTestToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(testJWTSecret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func bearerHeader(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestReadOnlyTokenScope(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"JWT_SECRET": testJWTSecret})
+	exp := time.Now().Add(time.Hour).Unix()
+	readOnly := signTestToken(t, map[string]interface{}{"sub": "reader", "scope": "books:read", "exp": exp})
+	writer := signTestToken(t, map[string]interface{}{"sub": "writer", "scope": "books:read books:write", "exp": exp})
+	body := `{"title":"토지","author":"박경리","year":1969}`
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", bearerHeader(readOnly))
+	if rec.Code != http.StatusOK {
+		t.Errorf("읽기 전용 토큰 GET 상태 코드 = %d, 원하는 값 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, h, "POST", "/v1/books", body, bearerHeader(readOnly))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("읽기 전용 토큰 POST 상태 코드 = %d, 원하는 값 403 (%s)", rec.Code, rec.Body.String())
+	}
+	var forbidden struct {
+		RequiredScope string `json:"required_scope"`
+	}
+	decodeResponse(t, rec, &forbidden)
+	if forbidden.RequiredScope != "books:write" {
+		t.Errorf("required_scope = %q, 원하는 값 books:write", forbidden.RequiredScope)
+	}
+
+	rec = doRequest(t, h, "POST", "/v1/books", body, bearerHeader(writer))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("쓰기 토큰 POST 상태 코드 = %d, 원하는 값 201 (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRouteScopeOverride(t *testing.T) {
+	h := newTestHandler(t, map[string]string{
+		"JWT_SECRET":       testJWTSecret,
+		"JWT_ROUTE_SCOPES": "GetBooks=books:list",
+	})
+	token := signTestToken(t, map[string]interface{}{"sub": "reader", "scope": "books:read", "exp": time.Now().Add(time.Hour).Unix()})
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", bearerHeader(token))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("GetBooks 상태 코드 = %d, 원하는 값 403", rec.Code)
+	}
+	rec = doRequest(t, h, "GET", "/v1/books/count", "", bearerHeader(token))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GetBooksCount 상태 코드 = %d, 원하는 값 200 (%s)", rec.Code, rec.Body.String())
+	}
+}
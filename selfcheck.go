@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// 책 테이블에 있어야 하는 컬럼 (조회 순서 기준)
+var expectedColumns = []string{"id", "title", "author", "year", "isbn", "regdate", "updated_at", "version"}
+
+// 시작 점검 항목 결과
+type checkResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"` // ok, warn, fail, skipped
+	Critical bool   `json:"critical"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// 시작 점검 결과 요약
+type startupReport struct {
+	OK     bool          `json:"ok"`
+	Checks []checkResult `json:"checks"`
+}
+
+// 시작 점검 실행 (필수 환경변수, DB 연결, 테이블 구조 등)
+// 치명적 항목이 실패하면 그 이후의 DB 관련 항목은 건너뛴다.
+func runStartupChecks(config *Config) startupReport {
+	report := startupReport{OK: true}
+	add := func(result checkResult) {
+		if result.Critical && result.Status == "fail" {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	skip := func(name string, critical bool) {
+		add(checkResult{Name: name, Status: "skipped", Critical: critical, Detail: "이전 점검 실패로 건너뜀"})
+	}
+
+	// 필수 환경변수 (메모리 저장소 사용 시 DB 설정은 필요 없음)
+	required := map[string]bool{"API_KEY": len(config.APIKeys) == 0}
+	if config.DBDriver != "memory" {
+		required["DB_SERVER"] = config.DBServer == ""
+		required["DB_USER"] = config.DBUser == ""
+		required["DB_PASSWORD"] = config.DBPassword == ""
+		required["DB_NAME"] = config.DBName == ""
+	}
+	var missing []string
+	for name, empty := range required {
+		if empty {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing) // map 순회 순서와 무관하게 출력 고정
+		add(checkResult{Name: "env", Status: "fail", Critical: true, Detail: "누락: " + strings.Join(missing, ", ")})
+	} else {
+		add(checkResult{Name: "env", Status: "ok", Critical: true})
+	}
+
+	// API 키 강도 (경고만)
+	weakKeys := 0
+	for key := range config.APIKeys {
+		if len(key) < 16 {
+			weakKeys++
+		}
+	}
+	if weakKeys > 0 {
+		add(checkResult{Name: "api_key", Status: "warn", Detail: fmt.Sprintf("API_KEY 중 16자 미만인 키가 %d개 있습니다", weakKeys)})
+	} else {
+		add(checkResult{Name: "api_key", Status: "ok"})
+	}
+
+	if !report.OK {
+		skip("db", true)
+		skip("table", true)
+		return report
+	}
+
+	if config.DBDriver == "memory" {
+		add(checkResult{Name: "db", Status: "skipped", Detail: "DB_DRIVER=memory"})
+		add(checkResult{Name: "table", Status: "skipped", Detail: "DB_DRIVER=memory"})
+		return report
+	}
+
+	// DB 연결
+	if err := connectDB(config); err != nil {
+		add(checkResult{Name: "db", Status: "fail", Critical: true, Detail: err.Error()})
+		skip("table", true)
+		return report
+	}
+	add(checkResult{Name: "db", Status: "ok", Critical: true})
+
+	// 테이블 존재 및 컬럼 구성
+	add(checkBookTable())
+
+	// 변경 이력 테이블 (쓰기마다 같은 트랜잭션에서 기록하므로 없으면 쓰기가 모두 실패)
+	auditCtx, auditCancel := dbContext(context.Background())
+	_, err := db.ExecContext(auditCtx, "SELECT TOP 0 * FROM "+auditTable)
+	auditCancel()
+	if err != nil {
+		add(checkResult{Name: "audit_table", Status: "fail", Critical: true, Detail: err.Error()})
+	} else {
+		add(checkResult{Name: "audit_table", Status: "ok", Critical: true})
+	}
+
+	// 할당량 테이블 (쓰기 할당량 사용 시, 없으면 할당량이 적용되지 않으므로 경고)
+	if config.WriteQuotaPerDay > 0 {
+		ctx, cancel := dbContext(context.Background())
+		_, err := db.ExecContext(ctx, "SELECT TOP 0 * FROM "+quotaTable)
+		cancel()
+		if err != nil {
+			add(checkResult{Name: "quota_table", Status: "warn", Detail: err.Error()})
+		} else {
+			add(checkResult{Name: "quota_table", Status: "ok"})
+		}
+	}
+
+	return report
+}
+
+// 책 테이블 점검 (컬럼 타입 정보도 함께 저장)
+func checkBookTable() checkResult {
+	result := checkResult{Name: "table", Critical: true}
+
+	ctx, cancel := dbContext(context.Background())
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT TOP 0 * FROM "+bookTable)
+	if err != nil {
+		result.Status = "fail"
+		result.Detail = err.Error()
+		return result
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		result.Status = "fail"
+		result.Detail = err.Error()
+		return result
+	}
+
+	var columns []string
+	for _, ct := range types {
+		name := strings.ToLower(ct.Name())
+		columns = append(columns, name)
+		columnTypes[name] = ct
+	}
+
+	required := append([]string{}, expectedColumns...)
+	if multiTenant() {
+		required = append(required, "tenant_id")
+	}
+	if appConfig.SoftDelete {
+		required = append(required, "deleted_at")
+	}
+
+	var missing []string
+	for _, name := range required {
+		if _, ok := columnTypes[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		result.Status = "fail"
+		result.Detail = fmt.Sprintf("누락된 컬럼: %s (실제 컬럼: %s)", strings.Join(missing, ", "), strings.Join(columns, ", "))
+		return result
+	}
+
+	result.Status = "ok"
+	result.Detail = "컬럼: " + strings.Join(columns, ", ")
+	return result
+}
+
+// 시작 점검 결과 출력 (치명적 항목 실패 시 종료)
+func logStartupReport(report startupReport) {
+	data, _ := json.Marshal(report)
+	log.Printf("시작 점검 결과: %s", data)
+
+	for _, check := range report.Checks {
+		if check.Status == "warn" {
+			log.Printf("Warning: 시작 점검 경고 [%s] %s", check.Name, check.Detail)
+		}
+	}
+
+	if !report.OK {
+		var failed []string
+		for _, check := range report.Checks {
+			if check.Critical && check.Status == "fail" {
+				failed = append(failed, fmt.Sprintf("[%s] %s", check.Name, check.Detail))
+			}
+		}
+		log.Fatalf("시작 점검 실패: %s", strings.Join(failed, "; "))
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// 종료 진행 중 여부 (종료 시그널을 받으면 true, /ready가 503으로 응답)
+var draining atomic.Bool
+
+// 처리 중인 요청 수 (종료 시 남은 요청 수를 로그로 남기는 데 사용)
+var inFlightRequests atomic.Int64
+
+// 처리 중인 요청 수 집계 미들웨어 (가장 바깥에 등록)
+func inFlightMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlightRequests.Add(1)
+		defer inFlightRequests.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 요청 테넌트 컨텍스트 키
+const tenantKey contextKey = "tenant"
+
+// API_KEY_TENANTS 파싱 ("키:테넌트,키:테넌트" 형식)
+func parseTenantKeys(value string) (map[string]string, error) {
+	tenants := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, tenant, ok := strings.Cut(pair, ":")
+		key, tenant = strings.TrimSpace(key), strings.TrimSpace(tenant)
+		if !ok || key == "" || tenant == "" {
+			return nil, fmt.Errorf("잘못된 API_KEY_TENANTS 항목입니다 (키:테넌트 형식이어야 함)")
+		}
+		tenants[key] = tenant
+	}
+	return tenants, nil
+}
+
+// 멀티 테넌시 사용 여부
+func multiTenant() bool {
+	return appConfig != nil && len(appConfig.TenantKeys) > 0
+}
+
+// 요청 컨텍스트의 테넌트 (멀티 테넌시 미사용 시 빈 문자열)
+func tenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
+
+// 기존 WHERE 절에 덧붙일 테넌트 조건
+func tenantCondition(ctx context.Context) (string, []interface{}) {
+	tenant := tenantFromContext(ctx)
+	if tenant == "" {
+		return "", nil
+	}
+	return " AND tenant_id = ?", []interface{}{tenant}
+}
+
+// INSERT 문에 덧붙일 테넌트 컬럼, 플레이스홀더, 값
+func tenantInsert(ctx context.Context) (string, string, []interface{}) {
+	tenant := tenantFromContext(ctx)
+	if tenant == "" {
+		return "", "", nil
+	}
+	return ", tenant_id", ", ?", []interface{}{tenant}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 요청 처리 제한 시간 미들웨어 (REQUEST_TIMEOUT, 0이면 사용 안 함)
+//
+// 핸들러가 timeout 안에 끝나지 않으면 요청 컨텍스트를 취소하고 503과 JSON 에러로 응답한다.
+// http.TimeoutHandler와 달리 응답을 버퍼에 모으지 않으므로 스트리밍 응답(목록, CSV 내보내기)도 그대로 나간다.
+// 이미 응답을 쓰기 시작한 뒤에는 상태 코드를 바꿀 수 없으므로 컨텍스트 취소로 핸들러가 끝나기를 기다린다.
+// 요청 로그 미들웨어 안쪽에 등록해야 시간 초과 응답도 요청 ID와 함께 503으로 기록된다.
+// CSV 내보내기는 EXPORT_TIMEOUT을 따로 적용하므로 이 제한에서 제외한다.
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExportRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, h: http.Header{}}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+					close(done)
+				}()
+				next.ServeHTTP(tw, r)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				tw.mu.Lock()
+				started := tw.started
+				tw.timedOut = !started
+				tw.mu.Unlock()
+
+				requestLogger(ctx).Warn("요청 처리 시간 초과", "timeout", timeout.String(), "response_started", started)
+				if started {
+					<-done
+					break
+				}
+				writeError(w, r, http.StatusServiceUnavailable, "요청 처리 시간이 초과되었습니다", nil)
+			}
+
+			// 핸들러 고루틴의 패닉은 서버가 처리하도록 요청 고루틴에서 다시 발생
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+		})
+	}
+}
+
+// 제한 시간 안에서 핸들러가 쓰는 ResponseWriter
+// 헤더는 별도로 모았다가 응답을 시작할 때 복사하고, 시간 초과 후의 쓰기는 http.ErrHandlerTimeout으로 거부한다.
+type timeoutWriter struct {
+	w        http.ResponseWriter
+	h        http.Header
+	mu       sync.Mutex
+	started  bool
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.started {
+		return
+	}
+	tw.start(status)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.started {
+		tw.start(http.StatusOK)
+	}
+	return tw.w.Write(b)
+}
+
+// 스트리밍 응답용 Flush
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	if !tw.started {
+		tw.start(http.StatusOK)
+	}
+	http.NewResponseController(tw.w).Flush()
+}
+
+// 모아 둔 헤더를 복사하고 상태 코드 기록 (mu를 잡은 상태에서 호출)
+func (tw *timeoutWriter) start(status int) {
+	dst := tw.w.Header()
+	for name, values := range tw.h {
+		dst[name] = values
+	}
+	tw.w.WriteHeader(status)
+	tw.started = true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// DB가 응답하지 않는 상황을 흉내 내는 저장소 (조회가 컨텍스트 만료까지 대기)
+type slowBookRepo struct {
+	BookRepository
+}
+
+func (slowBookRepo) Get(ctx context.Context, id string, includeDeleted bool) (Book, error) {
+	<-ctx.Done()
+	return Book{}, ctx.Err()
+}
+
+func TestDBQueryTimeout(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"DB_QUERY_TIMEOUT": "20ms"})
+	repo = slowBookRepo{repo}
+
+	start := time.Now()
+	rec := doRequest(t, h, "GET", "/v1/books/1", "", nil)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("상태 코드 = %d, 원하는 값 504 (%s)", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	decodeResponse(t, rec, &body)
+	if body.Error != "database timeout" {
+		t.Errorf("error = %q, 원하는 값 database timeout", body.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("응답까지 %s 걸렸습니다 (DB_QUERY_TIMEOUT 20ms)", elapsed)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	handlerDone := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(handlerDone)
+		<-r.Context().Done()
+	})
+	h := timeoutMiddleware(20 * time.Millisecond)(slow)
+
+	rec := doRequest(t, h, "GET", "/v1/books", "", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("상태 코드 = %d, 원하는 값 503 (%s)", rec.Code, rec.Body.String())
+	}
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		t.Error("시간 초과 후 핸들러의 컨텍스트가 취소되지 않았습니다")
+	}
+}
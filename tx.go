@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// 쓰기 트랜잭션 동시 실행 제한 세마포어 (nil이면 제한 없음)
+var txSemaphore chan struct{}
+
+// 대기 시간 안에 트랜잭션 슬롯을 얻지 못한 경우
+var errTxBusy = errors.New("동시 트랜잭션 한도 초과")
+
+// 쓰기 트랜잭션 실행 (fn이 nil을 반환하면 커밋, 아니면 롤백 - 드라이런 요청이면 항상 롤백)
+// 동시 실행 중인 트랜잭션이 MAX_CONCURRENT_TX에 도달하면 TX_WAIT_TIMEOUT 동안 대기 후 errTxBusy 반환
+// LOCK_TIMEOUT_MS가 설정되면 잠금 대기가 그 시간을 넘을 때 MSSQL 에러 1222로 즉시 실패한다.
+func withWriteTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if txSemaphore != nil {
+		timer := time.NewTimer(appConfig.TxWaitTimeout)
+		defer timer.Stop()
+
+		select {
+		case txSemaphore <- struct{}{}:
+			defer func() { <-txSemaphore }()
+		case <-timer.C:
+			return errTxBusy
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// LOCK_TIMEOUT은 세션 설정이므로 전용 연결에서 설정하고 반납 전에 기본값(-1, 무제한)으로 되돌린다
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if appConfig.LockTimeoutMS > 0 {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET LOCK_TIMEOUT %d", appConfig.LockTimeoutMS)); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SET LOCK_TIMEOUT -1")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if isDryRun(ctx) {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+// MSSQL 잠금 대기 시간 초과 에러(1222) 여부
+func isLockTimeout(err error) bool {
+	var mssqlErr mssql.Error
+	return errors.As(err, &mssqlErr) && mssqlErr.Number == 1222
+}
+
+// MSSQL 고유 제약/인덱스 위반 에러(2627, 2601) 여부
+func isUniqueViolation(err error) bool {
+	var mssqlErr mssql.Error
+	return errors.As(err, &mssqlErr) && (mssqlErr.Number == 2627 || mssqlErr.Number == 2601)
+}
+
+// 잠시 후 재시도하면 성공할 수 있는 쓰기 에러 여부 (503 + Retry-After로 응답)
+func isRetryableWriteError(err error) bool {
+	return errors.Is(err, errTxBusy) || isLockTimeout(err)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// 빌드 정보 (go build -ldflags "-X main.Version=1.2.3 -X main.Commit=abc123 -X main.BuildTime=2024-01-01T00:00:00Z"로 지정)
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// 빌드 정보 조회 (인증 불필요)
+func GetVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"version":    Version,
+		"commit":     Commit,
+		"build_time": BuildTime,
+	})
+}
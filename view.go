@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ?include=로 요청할 수 있는 계산 필드
+var derivedFields = map[string]struct{}{
+	"age":    {},
+	"decade": {},
+}
+
+// 책 응답 뷰 (DB에 저장하지 않는 계산 필드 포함)
+type bookView struct {
+	Book
+	Age    *int `json:"age,omitempty" xml:"age,omitempty"`
+	Decade *int `json:"decade,omitempty" xml:"decade,omitempty"`
+}
+
+// include 파라미터 파싱 (알 수 없는 필드면 400 응답 후 false)
+func includeParam(w http.ResponseWriter, r *http.Request) (map[string]struct{}, bool) {
+	raw := r.URL.Query().Get("include")
+	if raw == "" {
+		return nil, true
+	}
+
+	include := parseStringSet(raw)
+	for name := range include {
+		if _, ok := derivedFields[name]; !ok {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf("알 수 없는 include 필드입니다: %s (가능: age, decade)", name), nil)
+			return nil, false
+		}
+	}
+	return include, true
+}
+
+// 응답용 책 값 (include가 없으면 Book 그대로)
+func bookResponse(book Book, include map[string]struct{}) interface{} {
+	if len(include) == 0 {
+		return book
+	}
+	return newBookView(book, include)
+}
+
+// include로 요청한 계산 필드를 채운 뷰
+func newBookView(book Book, include map[string]struct{}) bookView {
+	view := bookView{Book: book}
+	if _, ok := include["age"]; ok {
+		age := time.Now().Year() - book.Year
+		view.Age = &age
+	}
+	if _, ok := include["decade"]; ok {
+		decade := book.Year / 10 * 10
+		view.Decade = &decade
+	}
+	return view
+}
+
+// 응답용 목록 항목 (fields가 있으면 요청한 필드만)
+func bookListItem(book Book, include map[string]struct{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return bookResponse(book, include)
+	}
+	return partialBook{view: newBookView(book, include), fields: fields}
+}
+
+// 응답용 책 목록
+func bookListResponse(list []Book, include map[string]struct{}, fields []string) interface{} {
+	if len(include) == 0 && len(fields) == 0 {
+		return list
+	}
+
+	views := make([]interface{}, len(list))
+	for i, book := range list {
+		views[i] = bookListItem(book, include, fields)
+	}
+	return views
+}
+
+// 키셋 페이지 응답 (?after= 사용 시, next_cursor가 null이면 마지막 페이지)
+type bookCursorPage struct {
+	Books      interface{} `json:"books" xml:"book"`
+	NextCursor *string     `json:"next_cursor" xml:"next_cursor,omitempty"`
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 다중 행 INSERT의 행당 최대 파라미터 수 (seq, title, author, year, isbn, 멀티 테넌시 사용 시 tenant_id)
+const writeBatchParamsPerRow = 6
+
+// MSSQL 파라미터 최대 개수(2100)를 넘지 않도록 제한한 배치 최대 행 수 (여유를 두고 2000개 기준)
+const maxWriteBatchRows = 2000 / writeBatchParamsPerRow
+
+// 쓰기 큐 요청
+type insertRequest struct {
+	book   Book
+	tenant string
+	actor  string // 변경 이력에 남길 요청 클라이언트
+	result chan insertResult
+}
+
+// 쓰기 큐 처리 결과
+type insertResult struct {
+	book Book
+	err  error
+}
+
+// 쓰기 큐 (nil이면 사용 안 함, CreateBook이 직접 INSERT)
+var insertQueue chan *insertRequest
+
+// 쓰기 큐와 워커 시작
+// 워커는 최대 batchMax건 또는 batchWait 동안 모인 요청을 하나의 다중 행 INSERT로 저장한다.
+func startInsertQueue(size, workers, batchMax int, batchWait time.Duration) {
+	insertQueue = make(chan *insertRequest, size)
+	for i := 0; i < workers; i++ {
+		go insertWorker(batchMax, batchWait)
+	}
+	log.Printf("쓰기 큐 사용: 크기 %d, 워커 %d, 배치 최대 %d건/%s", size, workers, batchMax, batchWait)
+}
+
+// 큐에 INSERT 요청을 넣고 배치가 커밋될 때까지 대기
+// 요청이 먼저 취소되어도 이미 배치에 포함된 행은 저장될 수 있다.
+func enqueueInsert(ctx context.Context, book Book) (Book, error) {
+	source := auditSourceFromContext(ctx)
+	req := &insertRequest{
+		book:   book,
+		tenant: source.Tenant,
+		actor:  source.Actor,
+		result: make(chan insertResult, 1),
+	}
+
+	select {
+	case insertQueue <- req:
+	case <-ctx.Done():
+		return Book{}, ctx.Err()
+	}
+
+	select {
+	case res := <-req.result:
+		return res.book, res.err
+	case <-ctx.Done():
+		return Book{}, ctx.Err()
+	}
+}
+
+// 쓰기 큐 워커
+func insertWorker(batchMax int, batchWait time.Duration) {
+	for first := range insertQueue {
+		batch := []*insertRequest{first}
+
+		timer := time.NewTimer(batchWait)
+	collect:
+		for len(batch) < batchMax {
+			select {
+			case req, ok := <-insertQueue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, req)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		flushInsertBatch(batch)
+	}
+}
+
+// 앞의 seq 컬럼을 먼저 읽고 나머지를 scanBook에 넘기는 행
+type seqRow struct {
+	rows *sql.Rows
+	seq  *int
+}
+
+func (r seqRow) Scan(dest ...interface{}) error {
+	return r.rows.Scan(append([]interface{}{r.seq}, dest...)...)
+}
+
+// 모인 요청을 하나의 트랜잭션에서 다중 행 INSERT로 저장하고 각 요청에 결과 전달
+// 변경 이력도 같은 트랜잭션에서 요청별 클라이언트/테넌트로 기록한다.
+// OUTPUT 순서는 VALUES 순서와 같다는 보장이 없으므로 MERGE로 요청 순번(seq)을 함께 반환받는다.
+func flushInsertBatch(batch []*insertRequest) {
+	sourceColumns := "seq, title, author, year, isbn"
+	insertColumns := "title, author, year, isbn, regdate, updated_at, version"
+	insertValues := "s.title, s.author, s.year, s.isbn, GETUTCDATE(), GETUTCDATE(), 1"
+	rowPlaceholder := "(?, ?, ?, ?, ?)"
+	if multiTenant() {
+		sourceColumns += ", tenant_id"
+		insertColumns += ", tenant_id"
+		insertValues += ", s.tenant_id"
+		rowPlaceholder = "(?, ?, ?, ?, ?, ?)"
+	}
+
+	rowsSQL := make([]string, len(batch))
+	var args []interface{}
+	for i, req := range batch {
+		rowsSQL[i] = rowPlaceholder
+		args = append(args, i, req.book.Title, req.book.Author, req.book.Year, nullableISBN(req.book.ISBN))
+		if multiTenant() {
+			args = append(args, req.tenant)
+		}
+	}
+
+	query := fmt.Sprintf(`MERGE INTO %s AS t
+USING (VALUES %s) AS s (%s)
+ON 1 = 0
+WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)
+OUTPUT s.seq, %s;`,
+		bookTable, strings.Join(rowsSQL, ", "), sourceColumns, insertColumns, insertValues, insertedBookColumns)
+
+	created := make([]Book, len(batch))
+	ctx, cancel := dbContext(context.Background())
+	defer cancel()
+
+	err := withWriteTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var seq int
+			book, err := scanBook(seqRow{rows: rows, seq: &seq})
+			if err != nil {
+				return err
+			}
+			created[seq] = book
+		}
+		// 결과를 끝까지 읽어 커서가 닫힌 뒤 변경 이력 기록
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i, req := range batch {
+			source := auditSource{Actor: req.actor, Tenant: req.tenant}
+			if err := insertAudit(ctx, tx, source, auditCreate, nil, &created[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// 중복 키 하나로 배치 전체가 실패하면 어느 요청 때문인지 알 수 없으므로 한 건씩 다시 저장
+	if isUniqueViolation(err) && len(batch) > 1 {
+		for _, req := range batch {
+			flushInsertBatch([]*insertRequest{req})
+		}
+		return
+	}
+	err = translateUniqueViolation(err)
+	if err != nil {
+		log.Printf("쓰기 큐 배치 저장 에러 (%d건): %v", len(batch), err)
+	}
+
+	for i, req := range batch {
+		req.result <- insertResult{book: created[i], err: err}
+	}
+}